@@ -0,0 +1,181 @@
+package xbase
+
+import (
+	"fmt"
+	"io"
+)
+
+// defaultChunkSize is the default size of the chunk of records written at once.
+const defaultChunkSize = 1 << 20
+
+// BulkWriter appends records to the file of an XBase object in large chunks.
+// It is much faster than adding records one by one with the Add and Save methods.
+//
+// Each chunk is followed by the end of file mark, and the number of records
+// in the header is updated after each chunk, so the file stays valid
+// if the process stops. With the SyncOnSave durability option
+// the file is synced after each chunk.
+type BulkWriter struct {
+	db      *XBase
+	enc     *XBase // encodes the records
+	buf     []byte // encoded records not written yet
+	pending int64  // number of records in buf
+	size    int    // chunk size in bytes
+	broken  bool   // writing to the file failed
+	err     error
+}
+
+// NewBulkWriter returns a BulkWriter that appends records
+// to the file opened or created by db.
+// The structure, code page, location and write policies of db are used.
+func NewBulkWriter(db *XBase) *BulkWriter {
+	b := &BulkWriter{db: db, size: defaultChunkSize}
+	b.enc = &XBase{
+		header:        db.header,
+		fields:        db.fields,
+		encoder:       db.encoder,
+		loc:           db.loc,
+		policy:        db.policy,
+		fieldPolicies: db.fieldPolicies,
+	}
+	b.enc.makeBuf()
+	return b
+}
+
+// SetChunkSize sets the size in bytes of the chunk of records written at once.
+// The default size is 1 MB.
+func (b *BulkWriter) SetChunkSize(size int) {
+	b.size = size
+}
+
+// Write appends a record with the values of the fields in the order of the fields.
+// A nil value leaves the field empty.
+//
+// If a value cannot be written, the record is not added
+// and the following records can be written.
+// An error writing to the file is returned by all following calls.
+func (b *BulkWriter) Write(values []interface{}) (err error) {
+	if err := b.check(); err != nil {
+		return err
+	}
+	defer b.catchError("Write", &err)
+	if len(values) != len(b.enc.fields) {
+		panic(fmt.Errorf("%w: %d values for %d fields", ErrSchemaMismatch, len(values), len(b.enc.fields)))
+	}
+	b.newRec()
+	for i, v := range values {
+		b.enc.setFieldValue(i+1, v)
+	}
+	b.add()
+	return nil
+}
+
+// WriteMap appends a record with the field values by field names.
+// Fields missing in the map are left empty.
+func (b *BulkWriter) WriteMap(values map[string]interface{}) (err error) {
+	if err := b.check(); err != nil {
+		return err
+	}
+	defer b.catchError("WriteMap", &err)
+	b.newRec()
+	b.enc.setRecord(values)
+	b.add()
+	return nil
+}
+
+// WriteStruct appends a record with the field values
+// from the struct or pointer to struct v.
+// See XBase.Marshal for details.
+func (b *BulkWriter) WriteStruct(v interface{}) (err error) {
+	if err := b.check(); err != nil {
+		return err
+	}
+	defer b.catchError("WriteStruct", &err)
+	b.newRec()
+	b.enc.marshal(structValue(v))
+	b.add()
+	return nil
+}
+
+// Flush writes the buffered records to the file
+// and updates the number of records in the header.
+func (b *BulkWriter) Flush() (err error) {
+	if err := b.check(); err != nil {
+		return err
+	}
+	defer b.catchError("Flush", &err)
+	b.output(b.flush)
+	return nil
+}
+
+// Close flushes the buffered records.
+// The BulkWriter cannot be used after closing, the file stays open.
+func (b *BulkWriter) Close() error {
+	if err := b.Flush(); err != nil {
+		return err
+	}
+	b.err = ErrFileNotOpen
+	return nil
+}
+
+func (b *BulkWriter) check() error {
+	if b.err != nil {
+		return b.err
+	}
+	return b.db.err
+}
+
+// newRec prepares the record buffer for the next record.
+func (b *BulkWriter) newRec() {
+	b.enc.recNo = b.db.recCount() + b.pending + 1
+	b.enc.clearBuf()
+}
+
+// add adds the encoded record to the chunk.
+func (b *BulkWriter) add() {
+	b.buf = append(b.buf, b.enc.buf...)
+	b.pending++
+	if len(b.buf) >= b.size {
+		b.output(b.flush)
+	}
+}
+
+// flush writes the chunk followed by the end of file mark
+// and updates the header.
+func (b *BulkWriter) flush() {
+	if b.pending == 0 {
+		return
+	}
+	db := b.db
+	db.fileSeek(int64(db.header.DataOffset)+db.recCount()*int64(db.header.RecSize), io.SeekStart)
+	db.fileWrite(append(b.buf, fileEnd))
+	db.header.RecCount += uint32(b.pending)
+	db.isMod = true
+	db.writeHeader()
+	if db.durability.Sync == SyncOnSave {
+		db.sync()
+	}
+	b.buf = b.buf[:0]
+	b.pending = 0
+}
+
+// output runs fn that writes to the file.
+// If fn fails, the BulkWriter cannot be used anymore.
+func (b *BulkWriter) output(fn func()) {
+	b.broken = true
+	fn()
+	b.broken = false
+}
+
+// catchError converts a panic to an error with the record number.
+// It must be deferred.
+func (b *BulkWriter) catchError(op string, err *error) {
+	if p := recover(); p != nil {
+		b.enc.setError(&Error{Op: op}, p)
+		*err = b.enc.err
+		b.enc.err = nil
+		if b.broken {
+			b.err = *err
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package xbase
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkWriter(t *testing.T) {
+	db := New()
+	addFields(db)
+	db.CreateFile("./testdata/test.dbf")
+
+	b := NewBulkWriter(db)
+	d := time.Date(2021, 2, 12, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, b.Write([]interface{}{"Abc", true, 123, 123.45, d}))
+	require.NoError(t, b.WriteMap(nil))
+	require.NoError(t, b.WriteMap(map[string]interface{}{
+		"NAME": "Мышь", "FLAG": false, "COUNT": -321, "PRICE": -54.32, "DATE": d,
+	}))
+	require.NoError(t, b.Close())
+	require.Equal(t, int64(3), db.RecCount())
+	require.Equal(t, ErrFileNotOpen, b.Write(nil))
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+	require.Equal(t, readFile("./testdata/rec3.dbf"), readFile("./testdata/test.dbf"))
+}
+
+func TestBulkWriterCheckpoint(t *testing.T) {
+	db := New()
+	db.AddField("NAME", "C", 30)
+	db.AddField("COUNT", "N", 10)
+	db.CreateFile("./testdata/test.dbf")
+
+	// 41 bytes per record, a chunk holds 3 records
+	b := NewBulkWriter(db)
+	b.SetChunkSize(100)
+	for i := 1; i <= 4; i++ {
+		require.NoError(t, b.Write([]interface{}{fmt.Sprintf("Name %d", i), i}))
+	}
+
+	r := New()
+	r.OpenFile("./testdata/test.dbf", true)
+	require.Equal(t, int64(3), r.RecCount())
+	r.Last()
+	require.Equal(t, int64(3), r.FieldValueAsInt(2))
+	r.CloseFile()
+	require.NoError(t, r.Error())
+
+	require.NoError(t, b.Flush())
+	require.Equal(t, int64(4), db.RecCount())
+	db.GoTo(4)
+	require.Equal(t, "Name 4", db.FieldValueAsString(1))
+
+	// an invalid record is skipped
+	err := b.Write([]interface{}{"Name 5", 12345678901})
+	require.True(t, errors.Is(err, ErrValueOverflow))
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	require.Equal(t, int64(5), e.RecNo)
+	require.Equal(t, "COUNT", e.Field)
+	require.NoError(t, b.Write([]interface{}{"Name 5", 5}))
+	require.NoError(t, b.Close())
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+
+	r = New()
+	r.OpenFile("./testdata/test.dbf", true)
+	require.Equal(t, int64(5), r.RecCount())
+	r.Last()
+	require.Equal(t, "Name 5", r.FieldValueAsString(1))
+	r.CloseFile()
+	require.NoError(t, r.Error())
+}
+
+// syncStorage counts the Sync calls.
+type syncStorage struct {
+	*MemStorage
+	syncs int
+}
+
+func (s *syncStorage) Sync() error {
+	s.syncs++
+	return nil
+}
+
+func TestBulkWriterSync(t *testing.T) {
+	s := &syncStorage{MemStorage: NewMemStorage(nil)}
+	db := New()
+	db.AddField("NAME", "C", 30)
+	db.AddField("COUNT", "N", 10)
+	db.SetDurability(Durability{Sync: SyncOnSave})
+	db.CreateStorage(s)
+
+	b := NewBulkWriter(db)
+	b.SetChunkSize(100)
+	for i := 1; i <= 7; i++ {
+		require.NoError(t, b.Write([]interface{}{fmt.Sprintf("Name %d", i), i}))
+	}
+	require.Equal(t, 2, s.syncs)
+	require.NoError(t, b.Close())
+	require.Equal(t, 3, s.syncs)
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+	require.Equal(t, 4, s.syncs)
+}
+
+func TestBulkWriterReadOnly(t *testing.T) {
+	db := New()
+	db.OpenFile("./testdata/rec3.dbf", true)
+	b := NewBulkWriter(db)
+	require.NoError(t, b.WriteMap(map[string]interface{}{"NAME": "Abc"}))
+	err := b.Close()
+	require.True(t, errors.Is(err, ErrReadOnly))
+	require.Equal(t, err, b.Flush())
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func BenchmarkAppend(b *testing.B) {
+	values := []interface{}{"Name", 123}
+	newDB := func() *XBase {
+		db := New()
+		db.AddField("NAME", "C", 30)
+		db.AddField("COUNT", "N", 10)
+		db.CreateFile("./testdata/test_bench.dbf")
+		return db
+	}
+	b.Run("Save", func(b *testing.B) {
+		db := newDB()
+		for i := 0; i < b.N; i++ {
+			db.Add()
+			db.SetFieldValue(1, values[0])
+			db.SetFieldValue(2, values[1])
+			db.Save()
+		}
+		db.CloseFile()
+		require.NoError(b, db.Error())
+	})
+	b.Run("BulkWriter", func(b *testing.B) {
+		db := newDB()
+		w := NewBulkWriter(db)
+		for i := 0; i < b.N; i++ {
+			w.Write(values)
+		}
+		require.NoError(b, w.Close())
+		db.CloseFile()
+		require.NoError(b, db.Error())
+	})
+}
@@ -0,0 +1,114 @@
+package xbase
+
+import (
+	"fmt"
+	"time"
+)
+
+// Date is a calendar date without time of day and location.
+// The zero Date is written as an empty field.
+type Date struct {
+	Year  int        // year
+	Month time.Month // month of the year, January = 1
+	Day   int        // day of the month, starting from 1
+}
+
+// DateOf returns the calendar date of t in the location of t.
+func DateOf(t time.Time) Date {
+	var d Date
+	d.Year, d.Month, d.Day = t.Date()
+	return d
+}
+
+// ParseDate parses a date in the "2006-01-02" format.
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return Date{}, err
+	}
+	return DateOf(t), nil
+}
+
+// In returns the midnight of the date in the location.
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// IsZero reports whether d is the zero date.
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// String returns the date in the "2006-01-02" format.
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (d *Date) UnmarshalText(data []byte) error {
+	v, err := ParseDate(string(data))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// SetLocation sets the location of date field values.
+// Dates are read as midnight in the location,
+// time values are converted to the location before writing,
+// so the calendar date in the location is written.
+// By default dates are read as midnight UTC
+// and time values are written in their own location.
+func (db *XBase) SetLocation(loc *time.Location) {
+	db.loc = loc
+}
+
+// Location returns the location of date field values.
+func (db *XBase) Location() *time.Location {
+	if db.loc == nil {
+		return time.UTC
+	}
+	return db.loc
+}
+
+// FieldValueAsCivilDate returns the calendar date of the field of the current record.
+// The zero Date is returned for an empty field.
+// Field type must be date ("D"). Fields are numbered starting from 1.
+func (db *XBase) FieldValueAsCivilDate(fieldNo int) Date {
+	if db.err != nil {
+		return Date{}
+	}
+	defer db.wrapFieldError("FieldValueAsCivilDate", fieldNo)
+	return db.fieldByNo(fieldNo).civilDateValue(db.buf)
+}
+
+// Date returns a date value as calendar date.
+// The zero Date is returned for a null value.
+func (v Value) Date() Date {
+	if d, ok := v.v.(time.Time); ok {
+		return DateOf(d)
+	}
+	return Date{}
+}
+
+func (f *field) civilDateValue(recordBuf []byte) Date {
+	d := f.dateValue(recordBuf, nil)
+	if d.IsZero() {
+		return Date{}
+	}
+	return DateOf(d)
+}
+
+// dateIn returns the time converted to the location of date field values.
+func dateIn(t time.Time, loc *time.Location) time.Time {
+	if loc != nil {
+		t = t.In(loc)
+	}
+	return t
+}
@@ -0,0 +1,118 @@
+package xbase
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDate(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	d := DateOf(time.Date(2021, 2, 12, 1, 30, 0, 0, msk))
+	require.Equal(t, Date{2021, time.February, 12}, d)
+	require.Equal(t, "2021-02-12", d.String())
+	require.Equal(t, time.Date(2021, 2, 12, 0, 0, 0, 0, msk), d.In(msk))
+	require.False(t, d.IsZero())
+	require.True(t, Date{}.IsZero())
+
+	p, err := ParseDate("2021-02-12")
+	require.NoError(t, err)
+	require.Equal(t, d, p)
+	_, err = ParseDate("12.02.2021")
+	require.Error(t, err)
+
+	b, err := json.Marshal(d)
+	require.NoError(t, err)
+	require.Equal(t, `"2021-02-12"`, string(b))
+	var u Date
+	require.NoError(t, json.Unmarshal(b, &u))
+	require.Equal(t, d, u)
+}
+
+func TestLocation(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+
+	db := New()
+	addFields(db)
+	require.Equal(t, time.UTC, db.Location())
+	db.SetLocation(msk)
+	require.Equal(t, msk, db.Location())
+	db.CreateFile("./testdata/test.dbf")
+
+	// local midnight is the previous day in UTC
+	db.Add()
+	db.SetFieldValue(5, time.Date(2021, 2, 12, 0, 0, 0, 0, msk).UTC())
+	db.Save()
+	require.Equal(t, []byte("20210212"), db.FieldBytes(5))
+	require.Equal(t, time.Date(2021, 2, 12, 0, 0, 0, 0, msk), db.FieldValueAsDate(5))
+	require.Equal(t, Date{2021, time.February, 12}, db.FieldValueAsCivilDate(5))
+	require.Equal(t, time.Date(2021, 2, 12, 0, 0, 0, 0, msk), db.Record().Get("DATE").Time())
+
+	db.Add()
+	db.SetFieldValue(5, Date{2021, time.March, 1})
+	db.Save()
+	require.Equal(t, []byte("20210301"), db.FieldBytes(5))
+	require.Equal(t, Date{2021, time.March, 1}, db.Record().Get("DATE").Date())
+
+	db.Add()
+	db.Save()
+	require.Equal(t, Date{}, db.FieldValueAsCivilDate(5))
+	require.Equal(t, Date{}, db.Record().Get("DATE").Date())
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestMarshalDate(t *testing.T) {
+	type rec struct {
+		Name string `dbf:"NAME,C,20"`
+		Date Date   `dbf:"DATE"`
+	}
+
+	db := New()
+	db.AddStructFields(rec{})
+	db.CreateFile("./testdata/test.dbf")
+	db.AppendStruct(rec{Name: "Abc", Date: Date{2021, time.February, 12}})
+	require.NoError(t, db.Error())
+
+	var r rec
+	db.First()
+	db.Unmarshal(&r)
+	require.Equal(t, rec{Name: "Abc", Date: Date{2021, time.February, 12}}, r)
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestZeroDate(t *testing.T) {
+	type rec struct {
+		Name string `dbf:"NAME,C,20"`
+		Date Date   `dbf:"DATE"`
+	}
+
+	db := New()
+	db.AddStructFields(rec{})
+	db.CreateFile("./testdata/test.dbf")
+	db.AppendStruct(rec{Name: "Abc"})
+	require.NoError(t, db.Error())
+	require.Equal(t, "        ", string(db.FieldBytes(2)))
+
+	db.SetFieldValue(2, Date{2021, time.February, 12})
+	db.Save()
+	db.SetFieldValue(2, Date{})
+	db.Save()
+	require.Equal(t, "        ", string(db.FieldBytes(2)))
+
+	d := db.FieldValueAsCivilDate(2)
+	require.True(t, d.IsZero())
+	db.SetFieldValue(2, d)
+	db.Save()
+	require.NoError(t, db.Error())
+	require.Equal(t, "        ", string(db.FieldBytes(2)))
+	require.True(t, db.Record().Get("DATE").Null)
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
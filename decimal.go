@@ -0,0 +1,105 @@
+package xbase
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// RoundingMode defines how a decimal value is rounded
+// to the number of decimal places of a numeric field.
+type RoundingMode int
+
+// Rounding modes.
+const (
+	RoundHalfUp   RoundingMode = iota // to nearest, ties away from zero
+	RoundHalfEven                     // to nearest, ties to even
+	RoundTruncate                     // toward zero
+)
+
+// FieldValueAsDecimal returns the exact value of the numeric field of the current record.
+// Field type must be numeric ("N"). Fields are numbered starting from 1.
+func (db *XBase) FieldValueAsDecimal(fieldNo int) *big.Rat {
+	if db.err != nil {
+		return new(big.Rat)
+	}
+	defer db.wrapFieldError("FieldValueAsDecimal", fieldNo)
+	f, buf, ok := db.readField(fieldNo, 'N')
+	if !ok {
+		return new(big.Rat)
+	}
+	return f.decimalValue(buf)
+}
+
+// SetFieldDecimal sets the exact value of the numeric field of the current record.
+// If the value has more decimal places than the field,
+// it is rounded according to mode.
+// To save the changes, you need to call the Save method.
+// Fields are numbered starting from 1.
+func (db *XBase) SetFieldDecimal(fieldNo int, value *big.Rat, mode RoundingMode) {
+	if db.err != nil {
+		return
+	}
+	defer db.wrapFieldError("SetFieldDecimal", fieldNo)
+	f := db.fieldByNo(fieldNo)
+	db.ownBuf()
+	tr := f.setDecimalValue(db.buf, value, mode, db.writePolicy(fieldNo))
+	db.isDirty = true
+	db.report(fieldNo, tr)
+}
+
+// Decimal returns a numeric value as exact decimal.
+func (v Value) Decimal() *big.Rat {
+	r := new(big.Rat)
+	if v.Kind == KindNumeric && !v.Null {
+		if _, ok := r.SetString(strings.TrimSpace(v.str)); !ok {
+			r.SetInt64(0)
+		}
+	}
+	return r
+}
+
+func (f *field) decimalValue(recordBuf []byte) *big.Rat {
+	f.checkType('N')
+	s := strings.TrimSpace(string(f.buffer(recordBuf)))
+	r := new(big.Rat)
+	if s == "" {
+		return r
+	}
+	if _, ok := r.SetString(s); !ok {
+		panic(fmt.Errorf("invalid numeric value: %q", s))
+	}
+	return r
+}
+
+func (f *field) setDecimalValue(recordBuf []byte, value *big.Rat, mode RoundingMode, wp *WritePolicy) *Truncation {
+	f.checkType('N')
+	return f.setNumber(recordBuf, formatDecimal(value, int(f.Dec), mode), wp)
+}
+
+// formatDecimal formats the value with dec decimal places.
+func formatDecimal(value *big.Rat, dec int, mode RoundingMode) string {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(dec)), nil)
+	num := new(big.Int).Mul(value.Num(), scale)
+	q, m := new(big.Int).QuoRem(num, value.Denom(), new(big.Int))
+	if m.Sign() != 0 && mode != RoundTruncate {
+		// compare the remainder with the half of the denominator
+		cmp := new(big.Int).Mul(m.Abs(m), big.NewInt(2)).Cmp(value.Denom())
+		if cmp > 0 || (cmp == 0 && (mode == RoundHalfUp || q.Bit(0) == 1)) {
+			q.Add(q, big.NewInt(int64(num.Sign())))
+		}
+	}
+
+	digits := q.String()
+	sign := ""
+	if q.Sign() < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	if dec == 0 {
+		return sign + digits
+	}
+	if len(digits) <= dec {
+		digits = strings.Repeat("0", dec-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-dec] + "." + digits[len(digits)-dec:]
+}
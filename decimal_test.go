@@ -0,0 +1,101 @@
+package xbase
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func rat(s string) *big.Rat {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		panic(s)
+	}
+	return r
+}
+
+func requireRat(t *testing.T, want string, got *big.Rat) {
+	require.Equal(t, 0, rat(want).Cmp(got), "want %s, got %s", want, got.RatString())
+}
+
+func TestFormatDecimal(t *testing.T) {
+	tests := []struct {
+		value string
+		dec   int
+		mode  RoundingMode
+		want  string
+	}{
+		{"123.45", 2, RoundHalfUp, "123.45"},
+		{"0.1", 2, RoundHalfUp, "0.10"},
+		{"-0.05", 2, RoundHalfUp, "-0.05"},
+		{"7", 0, RoundHalfUp, "7"},
+		{"2.345", 2, RoundHalfUp, "2.35"},
+		{"-2.345", 2, RoundHalfUp, "-2.35"},
+		{"2.345", 2, RoundHalfEven, "2.34"},
+		{"2.355", 2, RoundHalfEven, "2.36"},
+		{"-2.345", 2, RoundHalfEven, "-2.34"},
+		{"2.3451", 2, RoundHalfEven, "2.35"},
+		{"2.349", 2, RoundTruncate, "2.34"},
+		{"-2.349", 2, RoundTruncate, "-2.34"},
+		{"1/3", 3, RoundHalfUp, "0.333"},
+		{"2/3", 3, RoundHalfUp, "0.667"},
+		{"0.5", 0, RoundHalfEven, "0"},
+		{"-0.004", 2, RoundHalfUp, "0.00"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, formatDecimal(rat(tt.value), tt.dec, tt.mode), tt.value)
+	}
+}
+
+func TestFieldDecimalValue(t *testing.T) {
+	f := newField("Name", "N", 15, 2)
+	f.Offset = 3
+	recordBuf := []byte("   1234567890.10    ")
+	requireRat(t, "1234567890.1", f.decimalValue(recordBuf))
+}
+
+func TestFieldSetDecimalValue(t *testing.T) {
+	recordBuf := make([]byte, 20)
+	f := newField("NAME", "N", 8, 2)
+	f.Offset = 5
+	f.setDecimalValue(recordBuf, rat("123.455"), RoundHalfEven, nil)
+	require.Equal(t, []byte("  123.46"), recordBuf[5:13])
+}
+
+func TestFieldValueAsDecimal(t *testing.T) {
+	db := New()
+	db.OpenFile("./testdata/rec3.dbf", true)
+
+	db.First()
+	requireRat(t, "123.45", db.FieldValueAsDecimal(4))
+	db.Next()
+	requireRat(t, "0", db.FieldValueAsDecimal(4))
+	db.Next()
+	requireRat(t, "-54.32", db.FieldValueAsDecimal(4))
+	requireRat(t, "-321", db.Record().Get("COUNT").Decimal())
+
+	db.FieldValueAsDecimal(1)
+	require.Error(t, db.Error())
+}
+
+func TestSetFieldDecimal(t *testing.T) {
+	db := New()
+	addFields(db)
+	db.CreateFile("./testdata/test.dbf")
+
+	db.Add()
+	db.SetFieldDecimal(4, rat("0.125"), RoundHalfEven)
+	require.Equal(t, "0.12", db.FieldValueAsString(4))
+	db.SetFieldDecimal(4, rat("0.125"), RoundHalfUp)
+	require.Equal(t, "0.13", db.FieldValueAsString(4))
+	db.SetFieldValue(4, rat("-1.005"))
+	require.Equal(t, "-1.01", db.FieldValueAsString(4))
+
+	db.SetFieldDecimal(4, rat("1234567.1"), RoundHalfUp)
+	require.Error(t, db.Error())
+	db.ResetError()
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
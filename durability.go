@@ -0,0 +1,147 @@
+package xbase
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+)
+
+// SyncMode defines when the file is synced to disk.
+type SyncMode int
+
+// Sync modes.
+const (
+	SyncNone    SyncMode = iota // the operating system decides
+	SyncOnClose                 // the file is synced by Flush and CloseFile
+	SyncOnSave                  // the file is synced after every saved record
+)
+
+// Durability defines how changes reach the disk.
+// The zero value writes the header only by Flush and CloseFile
+// and leaves syncing to the operating system.
+type Durability struct {
+	// HeaderInterval is the number of added records after which
+	// the header with the record count is written.
+	// 1 writes it on every Save, 0 only by Flush and CloseFile.
+	HeaderInterval int
+
+	Sync SyncMode
+
+	// AtomicRewrite makes operations that rewrite the whole file,
+	// such as Transcode, write a temporary file that replaces the original one.
+	// It works for files opened by the OpenFile method.
+	AtomicRewrite bool
+}
+
+// SetDurability sets how changes reach the disk.
+func (db *XBase) SetDurability(d Durability) {
+	db.durability = d
+}
+
+// saved applies the durability settings after a record is saved.
+func (db *XBase) saved(added bool) {
+	d := &db.durability
+	if added && d.HeaderInterval > 0 {
+		db.unflushed++
+		if db.unflushed >= d.HeaderInterval {
+			db.writeHeader()
+		}
+	}
+	if d.Sync == SyncOnSave {
+		db.sync()
+	}
+}
+
+// syncOnClose syncs the file if required by the durability settings.
+func (db *XBase) syncOnClose() {
+	if db.durability.Sync != SyncNone {
+		db.sync()
+	}
+}
+
+// sync commits the file to disk if the storage supports it.
+func (db *XBase) sync() {
+	if s, ok := db.file.(interface{ Sync() error }); ok {
+		if err := s.Sync(); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// canRewrite reports whether the file can be rewritten atomically.
+func (db *XBase) canRewrite() bool {
+	if !db.durability.AtomicRewrite || db.name == "" {
+		return false
+	}
+	switch db.file.(type) {
+	case *os.File, *loadedFile:
+		return true
+	}
+	return false
+}
+
+// transcodeAtomic converts the file to a temporary file
+// and replaces the original file with it.
+func (db *XBase) transcodeAtomic(ctx context.Context, cp int, progress ProgressFunc) []TranscodeIssue {
+	if db.isReadOnly {
+		panic(ErrReadOnly)
+	}
+	name := db.name
+	fi, err := os.Stat(name)
+	if err != nil {
+		panic(err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*.tmp")
+	if err != nil {
+		panic(err)
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+	err = f.Chmod(fi.Mode().Perm())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	issues := db.transcodeTo(ctx, tmp, cp, progress)
+	syncFile(tmp)
+	recNo := db.recNo
+	db.close()
+	if err := os.Rename(tmp, name); err != nil {
+		panic(err)
+	}
+	syncDir(filepath.Dir(name))
+	db.openFile(name, false)
+	db.setCodePage(cp)
+	db.goTo(recNo)
+	return issues
+}
+
+// syncFile commits the file with the given name to disk.
+func syncFile(name string) {
+	f, err := os.OpenFile(name, os.O_RDWR, 0)
+	if err != nil {
+		panic(err)
+	}
+	err = f.Sync()
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		panic(err)
+	}
+}
+
+// syncDir commits the directory entries to disk, so that a renamed file
+// survives a crash. Not all systems can sync a directory,
+// so errors are ignored.
+func syncDir(dir string) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	f.Sync()
+	f.Close()
+}
@@ -0,0 +1,185 @@
+package xbase
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func recCountOnDisk(t *testing.T, name string) int64 {
+	db := New()
+	db.OpenFile(name, true)
+	n := db.RecCount()
+	db.CloseFile()
+	require.NoError(t, db.Error())
+	return n
+}
+
+func TestAppendFileEnd(t *testing.T) {
+	db := New()
+	addFields(db)
+	db.CreateFile("./testdata/test.dbf")
+	db.Add()
+	db.SetFieldValue(1, "Abc")
+	db.Save()
+
+	b, err := os.ReadFile("./testdata/test.dbf")
+	require.NoError(t, err)
+	require.Equal(t, int(db.header.DataOffset)+int(db.header.RecSize)+1, len(b))
+	require.Equal(t, fileEnd, b[len(b)-1])
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestWriteFileEnd(t *testing.T) {
+	b := readFile("./testdata/rec3.dbf")
+	require.Equal(t, fileEnd, b[len(b)-1])
+
+	edit := func(data []byte) []byte {
+		require.NoError(t, os.WriteFile("./testdata/test.dbf", data, 0666))
+		db := New()
+		db.OpenFile("./testdata/test.dbf", false)
+		db.GoTo(2)
+		db.SetFieldValue(1, "Abc")
+		db.Save()
+		db.CloseFile()
+		require.NoError(t, db.Error())
+		b, err := os.ReadFile("./testdata/test.dbf")
+		require.NoError(t, err)
+		return b
+	}
+
+	// the missing mark is written
+	got := edit(b[:len(b)-1])
+	require.Len(t, got, len(b))
+	require.Equal(t, fileEnd, got[len(b)-1])
+
+	// data after the last record is not overwritten
+	extra := append(append([]byte(nil), b[:len(b)-1]...), "XYZ"...)
+	got = edit(extra)
+	require.Equal(t, "XYZ", string(got[len(extra)-3:]))
+}
+
+func TestWriteFileEndAppended(t *testing.T) {
+	createCountFile(t, "./testdata/test.dbf", 3)
+
+	db := New()
+	db.OpenFile("./testdata/test.dbf", false)
+	db.GoTo(2)
+	db.SetFieldValue(2, 20)
+	db.Save()
+
+	appendCount(t, "./testdata/test.dbf", 4, 5)
+	db.CloseFile()
+	require.NoError(t, db.Error())
+
+	// the records appended by another program are intact
+	b, err := os.ReadFile("./testdata/test.dbf")
+	require.NoError(t, err)
+	off := 32 + 2*32 + 1 + 3*41
+	require.Equal(t, " Name 4", string(b[off:off+7]))
+	require.Equal(t, off+2*41+1, len(b))
+	require.Equal(t, fileEnd, b[len(b)-1])
+}
+
+func TestHeaderInterval(t *testing.T) {
+	db := New()
+	addFields(db)
+	db.SetDurability(Durability{HeaderInterval: 2})
+	db.CreateFile("./testdata/test.dbf")
+
+	db.Add()
+	db.Save()
+	require.Equal(t, int64(0), recCountOnDisk(t, "./testdata/test.dbf"))
+	db.Add()
+	db.Save()
+	require.Equal(t, int64(2), recCountOnDisk(t, "./testdata/test.dbf"))
+
+	db.SetDurability(Durability{HeaderInterval: 1, Sync: SyncOnSave})
+	db.Add()
+	db.Save()
+	require.Equal(t, int64(3), recCountOnDisk(t, "./testdata/test.dbf"))
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestSyncOnSaveLoaded(t *testing.T) {
+	copyFile("./testdata/rec3.dbf", "./testdata/test.dbf")
+
+	db := New()
+	db.SetLoadMode(LoadWriteBack)
+	db.SetDurability(Durability{HeaderInterval: 1, Sync: SyncOnSave})
+	db.OpenFile("./testdata/test.dbf", false)
+	db.Add()
+	db.SetFieldValue(1, "Кот")
+	db.Save()
+	require.NoError(t, db.Error())
+	require.Equal(t, int64(4), recCountOnDisk(t, "./testdata/test.dbf"))
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestTranscodeAtomic(t *testing.T) {
+	copyFile("./testdata/rec3.dbf", "./testdata/test.dbf")
+
+	db := New()
+	db.SetDurability(Durability{AtomicRewrite: true})
+	db.OpenFile("./testdata/test.dbf", false)
+	db.GoTo(2)
+	issues := db.Transcode(1251)
+	require.Empty(t, issues)
+	require.NoError(t, db.Error())
+	require.Equal(t, int64(2), db.RecNo())
+	require.Equal(t, 1251, db.CodePage())
+	db.GoTo(3)
+	require.Equal(t, "Мышь", db.FieldValueAsString(1))
+
+	issues = db.Transcode(0)
+	require.Empty(t, issues)
+	require.Equal(t, "Мышь", db.FieldValueAsString(1))
+	db.SetFieldValue(1, "Кот")
+	db.Save()
+	db.CloseFile()
+	require.NoError(t, db.Error())
+
+	tmp, err := filepath.Glob("./testdata/test.dbf.*.tmp")
+	require.NoError(t, err)
+	require.Empty(t, tmp)
+
+	db = New()
+	db.OpenFile("./testdata/test.dbf", true)
+	require.Equal(t, 0, db.CodePage())
+	db.GoTo(3)
+	require.Equal(t, "Кот", db.FieldValueAsString(1))
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestTranscodeAtomicKeepsHeader(t *testing.T) {
+	copyFile("./testdata/rec3.dbf", "./testdata/test.dbf")
+	b, err := os.ReadFile("./testdata/test.dbf")
+	require.NoError(t, err)
+	for i := 12; i < 32; i++ {
+		if i != 29 {
+			b[i] = byte(i)
+		}
+	}
+	require.NoError(t, os.WriteFile("./testdata/test.dbf", b, 0666))
+
+	db := New()
+	db.SetDurability(Durability{AtomicRewrite: true})
+	db.OpenFile("./testdata/test.dbf", false)
+	db.Transcode(1251)
+	db.CloseFile()
+	require.NoError(t, db.Error())
+
+	got, err := os.ReadFile("./testdata/test.dbf")
+	require.NoError(t, err)
+	require.Equal(t, b[4:29], got[4:29])
+	require.Equal(t, b[30:32], got[30:32])
+	require.Equal(t, byte(0xC9), got[29])
+}
@@ -0,0 +1,156 @@
+package xbase
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors reported by XBase methods.
+// Use errors.Is to check for them.
+var (
+	ErrNotDBF           = errors.New("not DBF file")
+	ErrFileNotOpen      = errors.New("file not open")
+	ErrNoFields         = errors.New("file structure undefined")
+	ErrCodePage         = errors.New("unsupported code page")
+	ErrFieldOutOfRange  = errors.New("field number out of range")
+	ErrFieldNotFound    = errors.New("field not found")
+	ErrSchemaMismatch   = errors.New("schema mismatch")
+	ErrTypeMismatch     = errors.New("type mismatch")
+	ErrValueOverflow    = errors.New("field value overflow")
+	ErrEOF              = errors.New("file is EOF")
+	ErrBOF              = errors.New("file is BOF")
+	ErrReadOnly         = errors.New("file is read-only")
+	ErrUnsaved          = errors.New("current record has unsaved changes")
+	ErrRecCount         = errors.New("record count mismatch")
+	ErrStructureChanged = errors.New("file structure changed")
+)
+
+// Error describes an error that occurred when working with a DBF file.
+// Use errors.As to get it from the value returned by the Error method.
+type Error struct {
+	Op      string // name of the method
+	FieldNo int    // field number, 0 if unknown
+	Field   string // field name, empty if unknown
+	RecNo   int64  // current record number
+	Offset  int64  // file offset of the current record, 0 if there is no current record
+	Err     error  // underlying error
+
+	isField bool // error is related to a field
+}
+
+func (e *Error) Error() string {
+	s := "xbase: " + e.Op
+	if e.isField {
+		switch {
+		case e.Field == "":
+			s += fmt.Sprintf(": field %d", e.FieldNo)
+		case e.FieldNo == 0:
+			s += fmt.Sprintf(": field %q", e.Field)
+		default:
+			s += fmt.Sprintf(": field %d %q", e.FieldNo, e.Field)
+		}
+	}
+	return s + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// fieldError passes the number of the failed field to wrapError
+// from methods that process several fields.
+type fieldError struct {
+	fieldNo int
+	name    string
+	err     error
+}
+
+func (e *fieldError) Error() string {
+	return e.err.Error()
+}
+
+// onField adds the field number to a panic.
+// It must be deferred.
+func onField(fieldNo int) {
+	if r := recover(); r != nil {
+		panic(&fieldError{fieldNo: fieldNo, err: toError(r)})
+	}
+}
+
+// onFieldName adds the field name to a panic.
+// It must be deferred.
+func onFieldName(name string) {
+	if r := recover(); r != nil {
+		panic(&fieldError{name: name, err: toError(r)})
+	}
+}
+
+func toError(r interface{}) error {
+	if err, ok := r.(error); ok {
+		return err
+	}
+	return fmt.Errorf("%v", r)
+}
+
+func errUnsupportedCodePage(cp int) error {
+	return fmt.Errorf("%w: %d", ErrCodePage, cp)
+}
+
+// catchError converts a panic to an error
+// in functions that are not methods of XBase.
+// It must be deferred.
+func catchError(op string, err *error) {
+	if r := recover(); r != nil {
+		e := &Error{Op: op}
+		e.setCause(r)
+		*err = e
+	}
+}
+
+// setCause sets the underlying error from a panic value.
+func (e *Error) setCause(r interface{}) {
+	e.Err = toError(r)
+	if fe, ok := e.Err.(*fieldError); ok {
+		e.isField = true
+		e.FieldNo = fe.fieldNo
+		e.Field = fe.name
+		e.Err = fe.err
+	}
+}
+
+func (db *XBase) wrapError(op string) {
+	if r := recover(); r != nil {
+		db.setError(&Error{Op: op}, r)
+	}
+}
+
+func (db *XBase) wrapFieldError(op string, fieldNo int) {
+	if r := recover(); r != nil {
+		e := &Error{Op: op, FieldNo: fieldNo, isField: true}
+		if fieldNo >= 1 && fieldNo <= len(db.fields) {
+			e.Field = db.fields[fieldNo-1].name()
+		}
+		db.setError(e, r)
+	}
+}
+
+func (db *XBase) wrapFieldNameError(op string, name string) {
+	if r := recover(); r != nil {
+		db.setError(&Error{Op: op, Field: name, isField: true}, r)
+	}
+}
+
+func (db *XBase) setError(e *Error, r interface{}) {
+	e.setCause(r)
+	if e.isField && e.Field == "" && e.FieldNo >= 1 && e.FieldNo <= len(db.fields) {
+		e.Field = db.fields[e.FieldNo-1].name()
+	}
+	e.RecNo = db.recNo
+	if db.recNo >= 1 && db.recNo <= db.recCount() {
+		e.Offset = db.recOffset()
+	}
+	db.err = e
+	if db.isPanic {
+		panic(db.err)
+	}
+}
@@ -0,0 +1,135 @@
+package xbase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorIs(t *testing.T) {
+	db := New()
+	db.OpenFile("./testdata/rec3.dbf", true)
+	db.First()
+
+	db.FieldValueAsInt(0)
+	require.True(t, errors.Is(db.Error(), ErrFieldOutOfRange))
+	db.ResetError()
+
+	db.FieldValueAsInt(1)
+	require.True(t, errors.Is(db.Error(), ErrTypeMismatch))
+	db.ResetError()
+
+	db.Save()
+	require.True(t, errors.Is(db.Error(), ErrReadOnly))
+	db.ResetError()
+
+	db.Last()
+	db.Next()
+	db.Save()
+	require.True(t, errors.Is(db.Error(), ErrEOF))
+	db.ResetError()
+
+	db.First()
+	db.Prev()
+	db.Save()
+	require.True(t, errors.Is(db.Error(), ErrBOF))
+	db.ResetError()
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestErrorAs(t *testing.T) {
+	db := New()
+	db.AddField("NAME", "C", 3)
+	db.CreateFile("./testdata/test.dbf")
+	db.Add()
+	db.Save()
+	db.Add()
+	db.Save()
+
+	db.GoTo(2)
+	db.SetFieldValue(1, "Abcd")
+
+	var e *Error
+	require.True(t, errors.As(db.Error(), &e))
+	require.Equal(t, "SetFieldValue", e.Op)
+	require.Equal(t, 1, e.FieldNo)
+	require.Equal(t, "NAME", e.Field)
+	require.Equal(t, int64(2), e.RecNo)
+	require.Equal(t, int64(32+32+1+4), e.Offset)
+	require.True(t, errors.Is(e, ErrValueOverflow))
+	require.Equal(t, `xbase: SetFieldValue: field 1 "NAME": field value overflow: value len 4, field len 3`, e.Error())
+	db.ResetError()
+
+	db.GoTo(3)
+	db.Del()
+	db.Save()
+	require.True(t, errors.As(db.Error(), &e))
+	require.True(t, errors.Is(e, ErrEOF))
+	require.Equal(t, int64(3), e.RecNo)
+	require.Equal(t, int64(0), e.Offset)
+
+	db.ResetError()
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestErrorNotDBF(t *testing.T) {
+	db := New()
+	db.OpenFile("./errors.go", true)
+	require.True(t, errors.Is(db.Error(), ErrNotDBF))
+}
+
+func TestErrorFieldName(t *testing.T) {
+	db := New()
+	db.AddField("NAME", "X", 10)
+	require.Equal(t, `xbase: AddField: field "NAME": invalid field type: got X, want C, N, L, D`, db.Error().Error())
+
+	var e *Error
+	require.True(t, errors.As(db.Error(), &e))
+	require.Equal(t, "NAME", e.Field)
+	require.Equal(t, 0, e.FieldNo)
+}
+
+func TestCreateAfterReadOnly(t *testing.T) {
+	db := New()
+	db.OpenFile("./testdata/rec3.dbf", true)
+	db.CloseFile()
+	require.NoError(t, db.Error())
+
+	db.CreateFile("./testdata/test.dbf")
+	require.Equal(t, int64(0), db.RecCount())
+	db.Add()
+	db.SetFieldValue(1, "Abc")
+	db.Save()
+	require.NoError(t, db.Error())
+	require.Equal(t, int64(1), db.RecCount())
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestReopenReadOnly(t *testing.T) {
+	db := New()
+	db.AddField("NAME", "C", 10)
+	db.CreateFile("./testdata/test.dbf")
+	db.Add()
+	db.Save()
+	db.CloseFile()
+	require.NoError(t, db.Error())
+
+	db.OpenFile("./testdata/test.dbf", true)
+	require.False(t, db.Modified())
+	db.CloseFile()
+	require.NoError(t, db.Error())
+
+	db.OpenFile("./testdata/test.dbf", true)
+	db.isMod = true
+	db.Flush()
+	require.True(t, errors.Is(db.Error(), ErrReadOnly))
+	db.ResetError()
+	db.isMod = false
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"math"
+	"math/big"
 	"strconv"
 	"strings"
 	"time"
@@ -38,6 +40,15 @@ func (f *field) name() string {
 	return string(f.Name[:i])
 }
 
+// Field flags (Visual FoxPro) are stored in the first byte of the filler.
+func (f *field) flags() byte {
+	return f.Filler[0]
+}
+
+func (f *field) setFlags(flags byte) {
+	f.Filler[0] = flags
+}
+
 // String utils
 
 func padRight(s string, width int) string {
@@ -162,17 +173,25 @@ func (f *field) setBuffer(recordBuf []byte, value string) {
 	copy(recordBuf[int(f.Offset):int(f.Offset)+int(f.Len)], value)
 }
 
+func (f *field) setBytes(recordBuf []byte, value []byte) {
+	fieldBuf := f.buffer(recordBuf)
+	n := copy(fieldBuf, value)
+	for i := n; i < len(fieldBuf); i++ {
+		fieldBuf[i] = ' '
+	}
+}
+
 // Check
 
 func (f *field) checkType(t byte) {
 	if t != f.Type {
-		panic(fmt.Errorf("type mismatch: got %q, want %q", string(t), string(f.Type)))
+		panic(fmt.Errorf("%w: got %q, want %q", ErrTypeMismatch, string(t), string(f.Type)))
 	}
 }
 
-func (f *field) checkLen(value string) {
-	if len(value) > int(f.Len) {
-		panic(fmt.Errorf("field value overflow: value len %d, field len %d", len(value), int(f.Len)))
+func (f *field) checkLen(length int) {
+	if length > int(f.Len) {
+		panic(fmt.Errorf("%w: value len %d, field len %d", ErrValueOverflow, length, int(f.Len)))
 	}
 }
 
@@ -205,14 +224,17 @@ func (f *field) boolValue(recordBuf []byte) bool {
 	return (b == 'T' || b == 't' || b == 'Y' || b == 'y')
 }
 
-func (f *field) dateValue(recordBuf []byte) time.Time {
+func (f *field) dateValue(recordBuf []byte, loc *time.Location) time.Time {
 	f.checkType('D')
 	s := string(f.buffer(recordBuf))
 	var d time.Time
 	if strings.Trim(s, " ") == "" {
 		return d
 	}
-	d, err := time.Parse("20060102", s)
+	if loc == nil {
+		loc = time.UTC
+	}
+	d, err := time.ParseInLocation("20060102", s, loc)
 	if err != nil {
 		panic(err)
 	}
@@ -230,6 +252,9 @@ func (f *field) intValue(recordBuf []byte) int64 {
 	if i > 0 {
 		s = s[0:i]
 	}
+	if s == "-" || s == "+" {
+		return 0
+	}
 	n, err := strconv.ParseInt(s, 10, 64)
 	if err != nil {
 		panic(err)
@@ -253,18 +278,30 @@ func (f *field) floatValue(recordBuf []byte) float64 {
 
 // Set value
 
-func (f *field) setStringValue(recordBuf []byte, value string, enc *encoding.Encoder) {
+func (f *field) setStringValue(recordBuf []byte, value string, enc *encoding.Encoder, wp *WritePolicy) *Truncation {
 	f.checkType('C')
 
+	s := value
 	if enc != nil && !isASCII(value) {
-		s, err := enc.String(value)
+		es, err := enc.String(value)
 		if err != nil {
 			panic(err)
 		}
-		value = s
+		s = es
 	}
-	f.checkLen(value)
-	f.setBuffer(recordBuf, padRight(value, int(f.Len)))
+	var t *Truncation
+	if len(s) > int(f.Len) && wp != nil && wp.String == StringTruncate {
+		s, _ = truncate(s, int(f.Len), enc == nil)
+		stored := s
+		if enc != nil {
+			// single-byte code page: one byte per character
+			stored = string([]rune(value)[:len(s)])
+		}
+		t = &Truncation{Value: value, Stored: stored}
+	}
+	f.checkLen(len(s))
+	f.setBuffer(recordBuf, padRight(s, int(f.Len)))
+	return t
 }
 
 func (f *field) setBoolValue(recordBuf []byte, value bool) {
@@ -276,59 +313,112 @@ func (f *field) setBoolValue(recordBuf []byte, value bool) {
 	f.setBuffer(recordBuf, s)
 }
 
-func (f *field) setDateValue(recordBuf []byte, value time.Time) {
+func (f *field) setDateValue(recordBuf []byte, value time.Time, loc *time.Location) {
 	f.checkType('D')
-	f.setBuffer(recordBuf, value.Format("20060102"))
+	f.setBuffer(recordBuf, dateIn(value, loc).Format("20060102"))
 }
 
-func (f *field) setIntValue(recordBuf []byte, value int64) {
+func (f *field) setIntValue(recordBuf []byte, value int64, wp *WritePolicy) *Truncation {
 	f.checkType('N')
 	s := strconv.FormatInt(value, 10)
 	if f.Dec > 0 {
 		s += "." + strings.Repeat("0", int(f.Dec))
 	}
-	f.checkLen(s)
-	f.setBuffer(recordBuf, padLeft(s, int(f.Len)))
+	return f.setNumber(recordBuf, s, wp)
 }
 
-func (f *field) setFloatValue(recordBuf []byte, value float64) {
+func (f *field) setFloatValue(recordBuf []byte, value float64, wp *WritePolicy) *Truncation {
 	f.checkType('N')
 	s := strconv.FormatFloat(value, 'f', int(f.Dec), 64)
-	f.checkLen(s)
+	return f.setNumber(recordBuf, s, wp)
+}
+
+// setNumber writes the formatted number.
+// A number that does not fit into the field is written according to the write policy.
+func (f *field) setNumber(recordBuf []byte, s string, wp *WritePolicy) *Truncation {
+	var t *Truncation
+	if len(s) > int(f.Len) && wp != nil && wp.Numeric != NumericFail {
+		stored := f.overflowNumber(s[0] == '-', wp.Numeric)
+		t = &Truncation{Value: s, Stored: stored}
+		s = stored
+	}
+	f.checkLen(len(s))
 	f.setBuffer(recordBuf, padLeft(s, int(f.Len)))
+	return t
 }
 
-func (f *field) setValue(recordBuf []byte, value interface{}, enc *encoding.Encoder) {
+// overflowNumber returns the value written instead of a number
+// that does not fit into the field.
+func (f *field) overflowNumber(negative bool, p NumericPolicy) string {
+	length, dec := int(f.Len), int(f.Dec)
+	if p == NumericStars {
+		return strings.Repeat("*", length)
+	}
+	intLen := length
+	if dec > 0 {
+		intLen -= dec + 1
+	}
+	s := ""
+	if negative {
+		if intLen == 1 && dec == 0 {
+			// The sign does not fit, zero is the closest value.
+			return "0"
+		}
+		s = "-"
+		intLen--
+	}
+	s += strings.Repeat("9", intLen)
+	if dec > 0 {
+		s += "." + strings.Repeat("9", dec)
+	}
+	return s
+}
+
+func (f *field) setValue(recordBuf []byte, value interface{}, enc *encoding.Encoder, loc *time.Location, wp *WritePolicy) *Truncation {
 	switch v := value.(type) {
 	case string:
-		f.setStringValue(recordBuf, v, enc)
+		return f.setStringValue(recordBuf, v, enc, wp)
 	case bool:
 		f.setBoolValue(recordBuf, v)
 	case int:
-		f.setIntValue(recordBuf, int64(v))
+		return f.setIntValue(recordBuf, int64(v), wp)
 	case int8:
-		f.setIntValue(recordBuf, int64(v))
+		return f.setIntValue(recordBuf, int64(v), wp)
 	case int16:
-		f.setIntValue(recordBuf, int64(v))
+		return f.setIntValue(recordBuf, int64(v), wp)
 	case int32:
-		f.setIntValue(recordBuf, int64(v))
+		return f.setIntValue(recordBuf, int64(v), wp)
 	case int64:
-		f.setIntValue(recordBuf, int64(v))
+		return f.setIntValue(recordBuf, int64(v), wp)
 	case uint8:
-		f.setIntValue(recordBuf, int64(v))
+		return f.setIntValue(recordBuf, int64(v), wp)
 	case uint16:
-		f.setIntValue(recordBuf, int64(v))
+		return f.setIntValue(recordBuf, int64(v), wp)
 	case uint32:
-		f.setIntValue(recordBuf, int64(v))
+		return f.setIntValue(recordBuf, int64(v), wp)
 	case uint64:
-		f.setIntValue(recordBuf, int64(v))
+		if v > math.MaxInt64 {
+			panic(fmt.Errorf("%w: value %d exceeds the int64 range", ErrValueOverflow, v))
+		}
+		return f.setIntValue(recordBuf, int64(v), wp)
 	case float32:
-		f.setFloatValue(recordBuf, float64(v))
+		return f.setFloatValue(recordBuf, float64(v), wp)
 	case float64:
-		f.setFloatValue(recordBuf, float64(v))
+		return f.setFloatValue(recordBuf, float64(v), wp)
 	case time.Time:
-		f.setDateValue(recordBuf, v)
+		f.setDateValue(recordBuf, v, loc)
+	case Date:
+		if v.IsZero() {
+			// The zero date is read from an empty field.
+			f.checkType('D')
+			f.setBytes(recordBuf, nil)
+			break
+		}
+		f.setDateValue(recordBuf, v.In(time.UTC), nil)
+	case *big.Rat:
+		return f.setDecimalValue(recordBuf, v, RoundHalfUp, wp)
 	default:
-		panic(fmt.Errorf("unsupport type value"))
+		panic(fmt.Errorf("%w: unsupported value type %T", ErrTypeMismatch, value))
 	}
+	return nil
 }
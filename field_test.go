@@ -124,7 +124,7 @@ func TestFieldDateValue(t *testing.T) {
 	recordBuf := []byte("   20200923    ")
 
 	d := time.Date(2020, 9, 23, 0, 0, 0, 0, time.UTC)
-	v := f.dateValue(recordBuf)
+	v := f.dateValue(recordBuf, nil)
 	require.Equal(t, d, v)
 }
 
@@ -134,6 +134,10 @@ func TestFieldIntValue(t *testing.T) {
 	recordBuf := []byte("      -2020    ")
 	v := f.intValue(recordBuf)
 	require.Equal(t, int64(-2020), v)
+
+	recordBuf = []byte("      +.5      ")
+	v = f.intValue(recordBuf)
+	require.Equal(t, int64(0), v)
 }
 
 func TestFieldFloatValue(t *testing.T) {
@@ -156,7 +160,7 @@ func TestFieldSetStringValue(t *testing.T) {
 	recordBuf := make([]byte, 20)
 	f := newField("NAME", "C", 5, 0)
 	f.Offset = 5
-	f.setStringValue(recordBuf, " Abc", nil)
+	f.setStringValue(recordBuf, " Abc", nil, nil)
 	require.Equal(t, []byte(" Abc "), recordBuf[5:10])
 }
 
@@ -173,7 +177,7 @@ func TestFieldSetDateValue(t *testing.T) {
 	f := newField("NAME", "D", 8, 0)
 	f.Offset = 5
 	d := time.Date(2020, 9, 23, 0, 0, 0, 0, time.UTC)
-	f.setDateValue(recordBuf, d)
+	f.setDateValue(recordBuf, d, nil)
 	require.Equal(t, []byte("20200923"), recordBuf[5:13])
 }
 
@@ -181,7 +185,7 @@ func TestFieldSetIntValue(t *testing.T) {
 	recordBuf := make([]byte, 20)
 	f := newField("NAME", "N", 5, 0)
 	f.Offset = 5
-	f.setIntValue(recordBuf, 123)
+	f.setIntValue(recordBuf, 123, nil)
 	require.Equal(t, []byte("  123"), recordBuf[5:10])
 }
 
@@ -189,6 +193,14 @@ func TestFieldSetFloatValue(t *testing.T) {
 	recordBuf := make([]byte, 20)
 	f := newField("NAME", "N", 8, 2)
 	f.Offset = 5
-	f.setFloatValue(recordBuf, 123.45)
+	f.setFloatValue(recordBuf, 123.45, nil)
 	require.Equal(t, []byte("  123.45"), recordBuf[5:13])
 }
+
+func TestFieldSetBytes(t *testing.T) {
+	recordBuf := []byte("**********")
+	f := newField("NAME", "C", 5, 0)
+	f.Offset = 3
+	f.setBytes(recordBuf, []byte("Ab"))
+	require.Equal(t, []byte("***Ab   **"), recordBuf)
+}
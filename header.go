@@ -2,7 +2,6 @@ package xbase
 
 import (
 	"encoding/binary"
-	"fmt"
 	"io"
 	"time"
 )
@@ -34,7 +33,7 @@ func (h *header) read(reader io.Reader) {
 		panic(err)
 	}
 	if h.DbfId != dbfId {
-		panic(fmt.Errorf("not DBF file"))
+		panic(ErrNotDBF)
 	}
 }
 
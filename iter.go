@@ -0,0 +1,64 @@
+//go:build go1.23
+// +build go1.23
+
+package xbase
+
+import "iter"
+
+// All returns an iterator over all records of the file
+// starting from the first one. It yields the record number and the record values.
+//
+// During the iteration the object is positioned at the yielded record,
+// so it can be changed and saved. The iteration stops at the end of the file
+// or on an error. Use the Error method to check the error after the iteration.
+func (db *XBase) All() iter.Seq2[int64, Record] {
+	return db.records("All", 1, false)
+}
+
+// Active returns an iterator over the records not marked as deleted.
+// See the All method for details.
+func (db *XBase) Active() iter.Seq2[int64, Record] {
+	return db.records("Active", 1, true)
+}
+
+// From returns an iterator over all records starting from the record recNo.
+// See the All method for details.
+func (db *XBase) From(recNo int64) iter.Seq2[int64, Record] {
+	return db.records("From", recNo, false)
+}
+
+// ActiveFrom returns an iterator over the records not marked as deleted
+// starting from the record recNo. See the All method for details.
+func (db *XBase) ActiveFrom(recNo int64) iter.Seq2[int64, Record] {
+	return db.records("ActiveFrom", recNo, true)
+}
+
+func (db *XBase) records(op string, from int64, skipDeleted bool) iter.Seq2[int64, Record] {
+	return func(yield func(int64, Record) bool) {
+		recNo := from
+		if recNo < 1 {
+			recNo = 1
+		}
+		for db.err == nil {
+			r, ok := db.seekRecord(op, recNo, skipDeleted)
+			if !ok || !yield(r.RecNo, r) {
+				return
+			}
+			recNo = r.RecNo + 1
+		}
+	}
+}
+
+// seekRecord positions the object to the first suitable record
+// starting from recNo and returns its values.
+// It returns false at the end of the file or on an error.
+func (db *XBase) seekRecord(op string, recNo int64, skipDeleted bool) (r Record, ok bool) {
+	defer db.wrapError(op)
+	for db.goTo(recNo); db.recNo <= db.recCount(); db.goTo(db.recNo + 1) {
+		if skipDeleted && db.buf[0] == '*' {
+			continue
+		}
+		return db.record(), true
+	}
+	return Record{}, false
+}
@@ -0,0 +1,122 @@
+//go:build go1.23
+// +build go1.23
+
+package xbase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIterAll(t *testing.T) {
+	db := New()
+	db.OpenFile("./testdata/rec3.dbf", true)
+
+	var recNos []int64
+	var names []string
+	for recNo, r := range db.All() {
+		recNos = append(recNos, recNo)
+		names = append(names, r.Get("NAME").String())
+	}
+	require.NoError(t, db.Error())
+	require.Equal(t, []int64{1, 2, 3}, recNos)
+	require.Equal(t, []string{"Abc", "", "Мышь"}, names)
+	require.Equal(t, true, db.EOF())
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestIterBreak(t *testing.T) {
+	db := New()
+	db.OpenFile("./testdata/rec3.dbf", true)
+
+	for recNo := range db.From(2) {
+		require.Equal(t, int64(2), recNo)
+		break
+	}
+	require.Equal(t, int64(2), db.RecNo())
+
+	var recNos []int64
+	for recNo := range db.From(0) {
+		recNos = append(recNos, recNo)
+	}
+	require.Equal(t, []int64{1, 2, 3}, recNos)
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestIterActive(t *testing.T) {
+	copyFile("./testdata/rec3.dbf", "./testdata/test1.dbf")
+
+	db := New()
+	db.OpenFile("./testdata/test1.dbf", false)
+
+	for recNo := range db.All() {
+		if recNo == 2 {
+			db.Del()
+			db.Save()
+		}
+	}
+	require.NoError(t, db.Error())
+
+	var recNos []int64
+	for recNo, r := range db.Active() {
+		require.Equal(t, false, r.Deleted)
+		recNos = append(recNos, recNo)
+	}
+	require.Equal(t, []int64{1, 3}, recNos)
+
+	recNos = nil
+	for recNo := range db.ActiveFrom(2) {
+		recNos = append(recNos, recNo)
+	}
+	require.Equal(t, []int64{3}, recNos)
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestIterError(t *testing.T) {
+	copyFile("./testdata/rec3.dbf", "./testdata/test1.dbf")
+
+	db := New()
+	db.SetSavePolicy(RejectUnsaved)
+	db.OpenFile("./testdata/test1.dbf", false)
+
+	count := 0
+	for range db.All() {
+		count++
+		db.SetFieldValue(1, "Edit")
+	}
+	require.Equal(t, 1, count)
+	require.True(t, errors.Is(db.Error(), ErrUnsaved))
+}
+
+func TestIterReuse(t *testing.T) {
+	db := New()
+	db.OpenFile("./testdata/rec3.dbf", true)
+
+	all := db.All()
+	from := db.From(2)
+	for i := 0; i < 2; i++ {
+		n := 0
+		for range all {
+			n++
+		}
+		require.Equal(t, 3, n)
+
+		n = 0
+		for range from {
+			n++
+		}
+		require.Equal(t, 2, n)
+	}
+	require.NoError(t, db.Error())
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
@@ -0,0 +1,206 @@
+package xbase
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// LoadMode defines whether a file is loaded into memory by the OpenFile method
+// and how the changes are written back.
+type LoadMode int
+
+// Load modes.
+const (
+	LoadOff       LoadMode = iota // the file is not loaded
+	LoadWriteBack                 // the changed parts are written back in place
+	LoadReplace                   // the file is replaced with a new one atomically
+)
+
+// pageSize is the size of the parts of a loaded file tracked for changes.
+const pageSize = 4096
+
+// SetLoadMode sets whether the OpenFile method loads the whole file into memory.
+// It must be called before opening the file.
+//
+// All navigation and changes of a loaded file are served from memory.
+// The changes are written to the file by the Flush and CloseFile methods.
+// With LoadWriteBack the changed parts of the file are written in one pass.
+// With LoadReplace the whole file is written to a temporary file
+// that replaces the original one, so the file is never left half-written.
+func (db *XBase) SetLoadMode(m LoadMode) {
+	db.loadMode = m
+}
+
+// Flush writes the header and the saved changes to the file.
+// For a file loaded into memory it writes the changes back
+// according to the load mode.
+func (db *XBase) Flush() {
+	if db.err != nil {
+		return
+	}
+	defer db.wrapError("Flush")
+	db.checkFile()
+	db.flush()
+}
+
+func (db *XBase) flush() {
+	if db.isMod {
+		db.header.setModDate(time.Now())
+		db.writeHeader()
+		db.writeFileEnd()
+	}
+	if lf, ok := db.file.(*loadedFile); ok {
+		lf.flush()
+	}
+	db.syncOnClose()
+}
+
+// loadedFile is a file loaded into memory.
+type loadedFile struct {
+	MemStorage
+	mode  LoadMode
+	name  string
+	file  *os.File       // the file to write the changes back, nil if not needed
+	perm  os.FileMode    // permissions of the file
+	dirty map[int64]bool // numbers of the changed pages
+}
+
+// loadFile loads the opened file into memory.
+func (db *XBase) loadFile(name string) {
+	f := db.file.(*os.File)
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		panic(err)
+	}
+	b, err := io.ReadAll(f)
+	if err != nil {
+		f.Close()
+		panic(err)
+	}
+	lf := &loadedFile{
+		MemStorage: MemStorage{buf: b},
+		mode:       db.loadMode,
+		name:       name,
+		file:       f,
+		perm:       fi.Mode().Perm(),
+		dirty:      make(map[int64]bool),
+	}
+	if db.isReadOnly || lf.mode == LoadReplace {
+		lf.file = nil
+		if err := f.Close(); err != nil {
+			panic(err)
+		}
+	}
+	db.file = lf
+}
+
+func (lf *loadedFile) Write(b []byte) (int, error) {
+	for p := lf.pos / pageSize; p*pageSize < lf.pos+int64(len(b)); p++ {
+		lf.dirty[p] = true
+	}
+	return lf.MemStorage.Write(b)
+}
+
+// flush writes the changes to the file.
+func (lf *loadedFile) flush() {
+	if len(lf.dirty) == 0 {
+		return
+	}
+	if lf.mode == LoadReplace {
+		lf.replace()
+	} else {
+		lf.writeBack()
+	}
+	lf.dirty = make(map[int64]bool)
+}
+
+// writeBack writes the runs of the changed pages in place.
+func (lf *loadedFile) writeBack() {
+	pages := make([]int64, 0, len(lf.dirty))
+	for p := range lf.dirty {
+		pages = append(pages, p)
+	}
+	sort.Slice(pages, func(i, j int) bool { return pages[i] < pages[j] })
+	for i := 0; i < len(pages); {
+		j := i + 1
+		for j < len(pages) && pages[j] == pages[j-1]+1 {
+			j++
+		}
+		start := pages[i] * pageSize
+		end := (pages[j-1] + 1) * pageSize
+		if end > int64(len(lf.buf)) {
+			end = int64(len(lf.buf))
+		}
+		if _, err := lf.file.WriteAt(lf.buf[start:end], start); err != nil {
+			panic(err)
+		}
+		i = j
+	}
+}
+
+// replace writes the contents to a temporary file and renames it to the file.
+func (lf *loadedFile) replace() {
+	f, err := os.CreateTemp(filepath.Dir(lf.name), filepath.Base(lf.name)+".*.tmp")
+	if err != nil {
+		panic(err)
+	}
+	defer os.Remove(f.Name())
+	if err := f.Chmod(lf.perm); err != nil {
+		f.Close()
+		panic(err)
+	}
+	if _, err := f.Write(lf.buf); err != nil {
+		f.Close()
+		panic(err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		panic(err)
+	}
+	if err := f.Close(); err != nil {
+		panic(err)
+	}
+	if err := os.Rename(f.Name(), lf.name); err != nil {
+		panic(err)
+	}
+	syncDir(filepath.Dir(lf.name))
+}
+
+// Sync writes the changes and commits the file to disk.
+func (lf *loadedFile) Sync() error {
+	var err error
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err = toError(r)
+			}
+		}()
+		lf.flush()
+	}()
+	if err == nil && lf.file != nil {
+		err = lf.file.Sync()
+	}
+	return err
+}
+
+// Close writes the changes and closes the file.
+func (lf *loadedFile) Close() (err error) {
+	defer func() {
+		if lf.file != nil {
+			if cerr := lf.file.Close(); err == nil {
+				err = cerr
+			}
+		}
+	}()
+	defer func() {
+		if r := recover(); r != nil {
+			err = toError(r)
+		}
+	}()
+	lf.flush()
+	return nil
+}
@@ -0,0 +1,87 @@
+package xbase
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func editCountFile(t *testing.T, mode LoadMode) {
+	db := New()
+	db.SetLoadMode(mode)
+	db.OpenFile("./testdata/test.dbf", false)
+	db.GoTo(500)
+	db.SetFieldValue(1, "Changed")
+	db.Save()
+	db.Add()
+	db.SetFieldValue(1, "Added")
+	db.SetFieldValue(2, 1001)
+	db.Save()
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestLoadWriteBack(t *testing.T) {
+	createCountFile(t, "./testdata/test.dbf", 1000)
+	editCountFile(t, LoadOff)
+	want := readFile("./testdata/test.dbf")
+
+	createCountFile(t, "./testdata/test.dbf", 1000)
+	db := New()
+	db.SetLoadMode(LoadWriteBack)
+	db.OpenFile("./testdata/test.dbf", false)
+	_, ok := db.file.(*loadedFile)
+	require.True(t, ok)
+
+	db.GoTo(500)
+	db.SetFieldValue(1, "Changed")
+	db.Save()
+	require.Len(t, db.file.(*loadedFile).dirty, 1)
+
+	// the file is not changed until Flush
+	r := New()
+	r.OpenFile("./testdata/test.dbf", true)
+	r.GoTo(500)
+	require.Equal(t, "Name 500", r.FieldValueAsString(1))
+
+	db.Flush()
+	require.NoError(t, db.Error())
+	require.Empty(t, db.file.(*loadedFile).dirty)
+	r.Cancel()
+	require.Equal(t, "Changed", r.FieldValueAsString(1))
+	r.CloseFile()
+	require.NoError(t, r.Error())
+
+	db.Add()
+	db.SetFieldValue(1, "Added")
+	db.SetFieldValue(2, 1001)
+	db.Save()
+	db.CloseFile()
+	require.NoError(t, db.Error())
+	require.Equal(t, want, readFile("./testdata/test.dbf"))
+}
+
+func TestLoadReplace(t *testing.T) {
+	createCountFile(t, "./testdata/test.dbf", 1000)
+	editCountFile(t, LoadOff)
+	want := readFile("./testdata/test.dbf")
+
+	createCountFile(t, "./testdata/test.dbf", 1000)
+	editCountFile(t, LoadReplace)
+	require.Equal(t, want, readFile("./testdata/test.dbf"))
+
+	tmp, err := filepath.Glob("./testdata/test.dbf.*.tmp")
+	require.NoError(t, err)
+	require.Empty(t, tmp)
+}
+
+func TestLoadReadOnly(t *testing.T) {
+	db := New()
+	db.SetLoadMode(LoadWriteBack)
+	db.OpenFile("./testdata/rec3.dbf", true)
+	require.Nil(t, db.file.(*loadedFile).file)
+	requireRec3(t, db)
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
@@ -0,0 +1,456 @@
+package xbase
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Struct fields are mapped to DBF fields by the "dbf" tag:
+//
+//	Name   string     `dbf:"NAME"`           // field NAME
+//	Price  float64    `dbf:"PRICE,omitempty"` // zero value is written as empty field
+//	Code   []byte     `dbf:"CODE,raw"`       // raw field bytes
+//	Date   *time.Time `dbf:"DATE"`           // nil for empty field
+//	Ignore int        `dbf:"-"`              // field is skipped
+//
+// Without a tag the name of the struct field in upper case is used.
+// Struct fields that have no matching DBF field are skipped.
+//
+// The tag can also set the field type, length and number of decimal places,
+// they are used by the AddStructFields method:
+//
+//	Price float64 `dbf:"PRICE,N,12,2"`
+//	Name  string  `dbf:"NAME,C,30,omitempty"`
+
+// AddStructFields adds fields to the structure of the DBF file
+// from the fields of the struct type of v.
+// The v parameter can be a struct, a pointer to struct or a nil pointer to struct.
+// This method can only be used before creating a new file.
+//
+// If the field type and length are not set in the tag,
+// they are derived from the type of the struct field:
+// bool - "L", time.Time and Date - "D", int64, int, uint64 and uint - "N" 19,
+// int32 - "N" 11, uint32 - "N" 10, int16 - "N" 6, uint16 - "N" 5,
+// int8 - "N" 4, uint8 - "N" 3. Strings require the length,
+// floats require the length and number of decimal places.
+//
+// The fields are checked as in the AddField method.
+// If any of them is invalid, no fields are added.
+func (db *XBase) AddStructFields(v interface{}) {
+	if db.err != nil {
+		return
+	}
+	defer db.wrapError("AddStructFields")
+	db.fields = append(db.fields, newStructFields(v)...)
+	db.index = nil
+}
+
+// newStructFields returns the DBF fields for the struct type of v.
+func newStructFields(v interface{}) []*field {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		panic(fmt.Errorf("%w: want struct, got %T", ErrTypeMismatch, v))
+	}
+	var fields []*field
+	for _, sf := range cachedStructFields(t) {
+		fields = append(fields, sf.newField(t.FieldByIndex(sf.index).Type))
+	}
+	return fields
+}
+
+// Unmarshal stores the field values of the current record
+// in the struct pointed to by v.
+func (db *XBase) Unmarshal(v interface{}) {
+	if db.err != nil {
+		return
+	}
+	defer db.wrapError("Unmarshal")
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		panic(fmt.Errorf("%w: want pointer to struct, got %T", ErrTypeMismatch, v))
+	}
+	db.unmarshal(rv.Elem())
+}
+
+// Marshal sets the field values of the current record
+// from the struct or pointer to struct v.
+// To save the changes, you need to call the Save method.
+func (db *XBase) Marshal(v interface{}) {
+	if db.err != nil {
+		return
+	}
+	defer db.wrapError("Marshal")
+	db.marshal(structValue(v))
+}
+
+// AppendStruct adds a new record with the field values
+// from the struct or pointer to struct v and saves it.
+func (db *XBase) AppendStruct(v interface{}) {
+	if db.err != nil {
+		return
+	}
+	defer db.wrapError("AppendStruct")
+	rv := structValue(v)
+	db.leaveRec()
+	db.isAdd = true
+	db.touch()
+	db.clearBuf()
+	defer db.cancelOnPanic()
+	db.marshal(rv)
+	db.save()
+}
+
+// cancelOnPanic discards the record being added if it could not be filled,
+// as the Cancel method does.
+func (db *XBase) cancelOnPanic() {
+	if r := recover(); r != nil {
+		db.cancel()
+		panic(r)
+	}
+}
+
+// ReadAll reads all records of the file into the slice pointed to by v.
+// The elements of the slice must be structs or pointers to structs.
+// The slice is truncated before reading.
+// After reading, the object is positioned at the end of the file.
+func (db *XBase) ReadAll(v interface{}) {
+	if db.err != nil {
+		return
+	}
+	defer db.wrapError("ReadAll")
+	db.readAll(context.Background(), v, nil)
+}
+
+// ReadAllContext reads all records into the slice as the ReadAll method does.
+// It stops with the context error when ctx is done,
+// the slice contains the records read before that.
+// If progress is not nil, it is called after each record.
+func (db *XBase) ReadAllContext(ctx context.Context, v interface{}, progress ProgressFunc) {
+	if db.err != nil {
+		return
+	}
+	defer db.wrapError("ReadAllContext")
+	db.readAll(ctx, v, progress)
+}
+
+func (db *XBase) readAll(ctx context.Context, v interface{}, progress ProgressFunc) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		panic(fmt.Errorf("%w: want pointer to slice, got %T", ErrTypeMismatch, v))
+	}
+	slice := rv.Elem()
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		panic(fmt.Errorf("%w: want slice of structs, got %T", ErrTypeMismatch, v))
+	}
+	slice.SetLen(0)
+	t := db.newTracker(ctx, progress)
+	for db.goTo(1); db.recNo <= db.recCount(); db.goTo(db.recNo + 1) {
+		elem := reflect.New(elemType)
+		db.unmarshal(elem.Elem())
+		if !isPtr {
+			elem = elem.Elem()
+		}
+		slice.Set(reflect.Append(slice, elem))
+		t.step()
+	}
+}
+
+func structValue(v interface{}) reflect.Value {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Errorf("%w: want struct, got %T", ErrTypeMismatch, v))
+	}
+	return rv
+}
+
+func (db *XBase) unmarshal(rv reflect.Value) {
+	for _, sf := range cachedStructFields(rv.Type()) {
+		fieldNo := db.fieldNoByName(sf.name)
+		if fieldNo == 0 {
+			continue
+		}
+		db.decodeField(fieldNo, sf, rv.FieldByIndex(sf.index))
+	}
+}
+
+func (db *XBase) marshal(rv reflect.Value) {
+	for _, sf := range cachedStructFields(rv.Type()) {
+		fieldNo := db.fieldNoByName(sf.name)
+		if fieldNo == 0 {
+			continue
+		}
+		db.encodeField(fieldNo, sf, rv.FieldByIndex(sf.index))
+	}
+}
+
+func (db *XBase) decodeField(fieldNo int, sf *structField, dst reflect.Value) {
+	defer onField(fieldNo)
+	f := db.fields[fieldNo-1]
+	if sf.raw {
+		setRaw(dst, f.buffer(db.buf))
+		return
+	}
+	v := db.readValue(fieldNo, f, db.buf)
+	if dst.Kind() == reflect.Ptr {
+		if v.Null {
+			dst.Set(reflect.Zero(dst.Type()))
+			return
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		dst = dst.Elem()
+	}
+	setValue(dst, v)
+}
+
+func (db *XBase) encodeField(fieldNo int, sf *structField, src reflect.Value) {
+	if sf.raw {
+		defer onField(fieldNo)
+		f := db.fields[fieldNo-1]
+		b := rawBytes(src)
+		f.checkLen(len(b))
+		db.touch()
+		f.setBytes(db.buf, b)
+		return
+	}
+	if src.Kind() == reflect.Ptr {
+		if src.IsNil() {
+			db.setFieldValue(fieldNo, nil)
+			return
+		}
+		src = src.Elem()
+	}
+	if sf.omitEmpty && src.IsZero() {
+		db.setFieldValue(fieldNo, nil)
+		return
+	}
+	db.setFieldValue(fieldNo, baseValue(src))
+}
+
+// setValue stores the decoded value in the struct field.
+func setValue(dst reflect.Value, v Value) {
+	if dst.Type() == timeType {
+		checkKind(v, KindDate, dst)
+		dst.Set(reflect.ValueOf(v.Time()))
+		return
+	}
+	if dst.Type() == dateType {
+		checkKind(v, KindDate, dst)
+		dst.Set(reflect.ValueOf(v.Date()))
+		return
+	}
+	switch dst.Kind() {
+	case reflect.String:
+		dst.SetString(v.String())
+	case reflect.Bool:
+		checkKind(v, KindLogical, dst)
+		dst.SetBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		checkKind(v, KindNumeric, dst)
+		n := v.Int()
+		if dst.OverflowInt(n) {
+			panic(fmt.Errorf("%w: value %d overflows %s", ErrValueOverflow, n, dst.Type()))
+		}
+		dst.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		checkKind(v, KindNumeric, dst)
+		n := v.Int()
+		if n < 0 || dst.OverflowUint(uint64(n)) {
+			panic(fmt.Errorf("%w: value %d overflows %s", ErrValueOverflow, n, dst.Type()))
+		}
+		dst.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		checkKind(v, KindNumeric, dst)
+		dst.SetFloat(v.Float())
+	default:
+		panic(fmt.Errorf("%w: unsupported struct field type %s", ErrTypeMismatch, dst.Type()))
+	}
+}
+
+func checkKind(v Value, k Kind, dst reflect.Value) {
+	if v.Kind != k {
+		panic(fmt.Errorf("%w: field type %q, struct field type %s", ErrTypeMismatch, v.Kind.String(), dst.Type()))
+	}
+}
+
+// baseValue converts the struct field value to a type accepted by field.setValue.
+func baseValue(src reflect.Value) interface{} {
+	if src.Type() == timeType || src.Type() == dateType {
+		return src.Interface()
+	}
+	switch src.Kind() {
+	case reflect.String:
+		return src.String()
+	case reflect.Bool:
+		return src.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return src.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return src.Uint()
+	case reflect.Float32, reflect.Float64:
+		return src.Float()
+	}
+	panic(fmt.Errorf("%w: unsupported struct field type %s", ErrTypeMismatch, src.Type()))
+}
+
+func setRaw(dst reflect.Value, b []byte) {
+	switch {
+	case dst.Kind() == reflect.String:
+		dst.SetString(string(b))
+	case dst.Type() == bytesType:
+		dst.SetBytes(append([]byte(nil), b...))
+	default:
+		panic(fmt.Errorf("%w: raw struct field must be string or []byte, got %s", ErrTypeMismatch, dst.Type()))
+	}
+}
+
+func rawBytes(src reflect.Value) []byte {
+	switch {
+	case src.Kind() == reflect.String:
+		return []byte(src.String())
+	case src.Type() == bytesType:
+		return src.Bytes()
+	}
+	panic(fmt.Errorf("%w: raw struct field must be string or []byte, got %s", ErrTypeMismatch, src.Type()))
+}
+
+// Struct fields
+
+var (
+	timeType  = reflect.TypeOf(time.Time{})
+	dateType  = reflect.TypeOf(Date{})
+	bytesType = reflect.TypeOf([]byte(nil))
+)
+
+type structField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	raw       bool
+	typ       string
+	length    int
+	dec       int
+}
+
+var structFieldsCache sync.Map // map[reflect.Type][]*structField
+
+func cachedStructFields(t reflect.Type) []*structField {
+	if fields, ok := structFieldsCache.Load(t); ok {
+		return fields.([]*structField)
+	}
+	fields, _ := structFieldsCache.LoadOrStore(t, structFields(t))
+	return fields.([]*structField)
+}
+
+func structFields(t reflect.Type) []*structField {
+	var fields []*structField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" || sf.Anonymous {
+			continue
+		}
+		tag := sf.Tag.Get("dbf")
+		if tag == "-" {
+			continue
+		}
+		f := &structField{name: sf.Name, index: sf.Index}
+		opts := strings.Split(tag, ",")
+		if name := strings.TrimSpace(opts[0]); name != "" {
+			f.name = name
+		}
+		f.name = normName(f.name)
+		f.parseOptions(opts[1:])
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// parseOptions parses the tag options.
+// The first number is the field length, the second one is the number of decimal places.
+func (f *structField) parseOptions(opts []string) {
+	numbers := 0
+	for _, opt := range opts {
+		opt = strings.TrimSpace(opt)
+		if n, err := strconv.Atoi(opt); err == nil {
+			if numbers == 0 {
+				f.length = n
+			} else {
+				f.dec = n
+			}
+			numbers++
+			continue
+		}
+		switch opt {
+		case "":
+		case "omitempty":
+			f.omitEmpty = true
+		case "raw":
+			f.raw = true
+		default:
+			f.typ = opt
+		}
+	}
+}
+
+// newField returns the DBF field for the struct field of type t.
+func (f *structField) newField(t reflect.Type) *field {
+	defer onFieldName(f.name)
+	typ, length := defaultField(t)
+	if f.typ != "" && !strings.EqualFold(f.typ[:1], typ) {
+		typ, length = f.typ, 0
+	}
+	if f.length != 0 {
+		length = f.length
+	}
+	return newField(f.name, typ, length, f.dec)
+}
+
+// defaultField returns the field type and length for the Go type.
+func defaultField(t reflect.Type) (typ string, length int) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType || t == dateType {
+		return "D", 0
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "L", 0
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return "N", 19
+	case reflect.Int32:
+		return "N", 11
+	case reflect.Uint32:
+		return "N", 10
+	case reflect.Int16:
+		return "N", 6
+	case reflect.Uint16:
+		return "N", 5
+	case reflect.Int8:
+		return "N", 4
+	case reflect.Uint8:
+		return "N", 3
+	case reflect.Float32, reflect.Float64:
+		return "N", 0
+	case reflect.String:
+		return "C", 0
+	}
+	return "", 0
+}
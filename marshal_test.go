@@ -0,0 +1,284 @@
+package xbase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testRec struct {
+	Name   string
+	Flag   *bool
+	Count  int32 `dbf:"count"`
+	Price  float64
+	Date   time.Time
+	Ignore string `dbf:"-"`
+	Extra  int
+	hidden int
+}
+
+func TestUnmarshal(t *testing.T) {
+	db := New()
+	db.OpenFile("./testdata/rec3.dbf", true)
+
+	db.GoTo(3)
+	var r testRec
+	db.Unmarshal(&r)
+	require.NoError(t, db.Error())
+	require.Equal(t, "Мышь", r.Name)
+	require.NotNil(t, r.Flag)
+	require.Equal(t, false, *r.Flag)
+	require.Equal(t, int32(-321), r.Count)
+	require.Equal(t, -54.32, r.Price)
+	require.Equal(t, time.Date(2021, 2, 12, 0, 0, 0, 0, time.UTC), r.Date)
+
+	db.GoTo(2)
+	db.Unmarshal(&r)
+	require.NoError(t, db.Error())
+	require.Equal(t, "", r.Name)
+	require.Nil(t, r.Flag)
+	require.Equal(t, int32(0), r.Count)
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestUnmarshalRaw(t *testing.T) {
+	db := New()
+	db.OpenFile("./testdata/rec3.dbf", true)
+
+	db.GoTo(1)
+	var r struct {
+		Name  []byte `dbf:"NAME,raw"`
+		Count string `dbf:"COUNT,raw"`
+	}
+	db.Unmarshal(&r)
+	require.NoError(t, db.Error())
+	require.Equal(t, []byte("Abc                 "), r.Name)
+	require.Equal(t, "  123", r.Count)
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestUnmarshalError(t *testing.T) {
+	db := New()
+	db.OpenFile("./testdata/rec3.dbf", true)
+	db.GoTo(1)
+
+	var r testRec
+	db.Unmarshal(r)
+	require.True(t, errors.Is(db.Error(), ErrTypeMismatch))
+	db.ResetError()
+
+	var r2 struct {
+		Name int
+	}
+	db.Unmarshal(&r2)
+	var e *Error
+	require.True(t, errors.As(db.Error(), &e))
+	require.True(t, errors.Is(e, ErrTypeMismatch))
+	require.Equal(t, "NAME", e.Field)
+	db.ResetError()
+
+	var r3 struct {
+		Count int8
+	}
+	db.GoTo(3)
+	db.Unmarshal(&r3)
+	require.True(t, errors.Is(db.Error(), ErrValueOverflow))
+	db.ResetError()
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestReadAll(t *testing.T) {
+	db := New()
+	db.OpenFile("./testdata/rec3.dbf", true)
+
+	var recs []testRec
+	db.ReadAll(&recs)
+	require.NoError(t, db.Error())
+	require.Len(t, recs, 3)
+	require.Equal(t, "Abc", recs[0].Name)
+	require.Equal(t, "", recs[1].Name)
+	require.Equal(t, "Мышь", recs[2].Name)
+	require.Equal(t, true, db.EOF())
+
+	var ptrs []*testRec
+	db.ReadAll(&ptrs)
+	require.NoError(t, db.Error())
+	require.Len(t, ptrs, 3)
+	require.Equal(t, int32(123), ptrs[0].Count)
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestAppendStruct(t *testing.T) {
+	db := New()
+	addFields(db)
+	db.CreateFile("./testdata/test.dbf")
+
+	d := time.Date(2021, 2, 12, 0, 0, 0, 0, time.UTC)
+	yes, no := true, false
+
+	db.AppendStruct(testRec{Name: "Abc", Flag: &yes, Count: 123, Price: 123.45, Date: d})
+	db.AppendStruct(&struct {
+		Name  string
+		Count int  `dbf:",omitempty"`
+		Price uint `dbf:",omitempty"`
+	}{})
+	db.AppendStruct(&testRec{Name: "Мышь", Flag: &no, Count: -321, Price: -54.32, Date: d})
+	require.Equal(t, int64(3), db.RecCount())
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+
+	testBytes := readFile("./testdata/test.dbf")
+	goldBytes := readFile("./testdata/rec3.dbf")
+	require.Equal(t, goldBytes, testBytes)
+}
+
+func TestMarshal(t *testing.T) {
+	db := New()
+	addFields(db)
+	db.CreateFile("./testdata/test.dbf")
+
+	db.Add()
+	db.Marshal(struct {
+		Name string `dbf:"NAME,raw"`
+	}{Name: "Abc"})
+	require.Equal(t, "Abc", db.FieldValueAsString(1))
+
+	db.Marshal(struct {
+		Name string `dbf:"NAME,raw"`
+	}{Name: "Too long raw value for field"})
+	require.True(t, errors.Is(db.Error(), ErrValueOverflow))
+	db.ResetError()
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestAddStructFields(t *testing.T) {
+	type rec struct {
+		Name  string    `dbf:"NAME,C,20"`
+		Flag  bool      `dbf:"FLAG"`
+		Count int       `dbf:"COUNT,N,5"`
+		Price float64   `dbf:"PRICE,N,9,2,omitempty"`
+		Date  time.Time `dbf:"DATE"`
+	}
+	db := New()
+	db.AddStructFields((*rec)(nil))
+	db.SetCodePage(866)
+	db.CreateFile("./testdata/test.dbf")
+	require.NoError(t, db.Error())
+
+	d := time.Date(2021, 2, 12, 0, 0, 0, 0, time.UTC)
+	db.AppendStruct(rec{Name: "Abc", Flag: true, Count: 123, Price: 123.45, Date: d})
+	db.Add()
+	db.Save()
+	db.AppendStruct(rec{Name: "Мышь", Count: -321, Price: -54.32, Date: d})
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+
+	testBytes := readFile("./testdata/test.dbf")
+	goldBytes := readFile("./testdata/rec3.dbf")
+	require.Equal(t, goldBytes, testBytes)
+}
+
+func TestAddStructFieldsDefaults(t *testing.T) {
+	db := New()
+	db.AddStructFields(struct {
+		A int64
+		B int16
+		C uint8
+		D *bool
+		E *time.Time
+		F string  `dbf:",10"`
+		G float64 `dbf:",12,3"`
+		H int     `dbf:",C,4"`
+	}{})
+	require.NoError(t, db.Error())
+	require.Equal(t, 8, db.FieldCount())
+
+	check := func(fieldNo int, name, typ string, length, dec int) {
+		n, tp, l, d := db.FieldInfo(fieldNo)
+		require.Equal(t, []interface{}{name, typ, length, dec}, []interface{}{n, tp, l, d})
+	}
+	check(1, "A", "N", 19, 0)
+	check(2, "B", "N", 6, 0)
+	check(3, "C", "N", 3, 0)
+	check(4, "D", "L", 1, 0)
+	check(5, "E", "D", 8, 0)
+	check(6, "F", "C", 10, 0)
+	check(7, "G", "N", 12, 3)
+	check(8, "H", "C", 4, 0)
+}
+
+func TestAddStructFieldsError(t *testing.T) {
+	db := New()
+	db.AddField("ID", "N", 5)
+	db.AddStructFields(struct {
+		Name  string `dbf:"NAME,C,20"`
+		Title string
+	}{})
+	require.Error(t, db.Error())
+	require.Equal(t, `xbase: AddStructFields: field "TITLE": invalid field len: got 0, want 0 < len <= 254`, db.Error().Error())
+	require.Equal(t, 1, db.FieldCount())
+
+	db.ResetError()
+	db.AddStructFields(1)
+	require.True(t, errors.Is(db.Error(), ErrTypeMismatch))
+}
+
+func TestAppendStructError(t *testing.T) {
+	db := New()
+	db.AddField("NAME", "C", 10)
+	db.AddField("COUNT", "N", 3)
+	db.CreateFile("./testdata/test.dbf")
+	db.SetSavePolicy(SaveUnsaved)
+
+	type rec struct {
+		Name  string
+		Count int
+	}
+	db.AppendStruct(rec{Name: "Abc", Count: 1})
+	db.AppendStruct(rec{Name: "half", Count: 12345})
+	require.True(t, errors.Is(db.Error(), ErrValueOverflow))
+	db.ResetError()
+	require.False(t, db.Modified())
+	require.Equal(t, "Abc", db.FieldValueAsString(1))
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+
+	db = New()
+	db.OpenFile("./testdata/test.dbf", true)
+	require.Equal(t, int64(1), db.RecCount())
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestAppendStructUintOverflow(t *testing.T) {
+	db := New()
+	db.AddStructFields(struct{ N uint64 }{})
+	db.CreateFile("./testdata/test.dbf")
+
+	db.AppendStruct(struct{ N uint64 }{N: 1<<64 - 1})
+	require.True(t, errors.Is(db.Error(), ErrValueOverflow))
+	db.ResetError()
+	require.Equal(t, int64(0), db.RecCount())
+
+	db.AppendStruct(struct{ N uint64 }{N: 1<<63 - 1})
+	require.NoError(t, db.Error())
+	require.Equal(t, int64(1<<63-1), db.FieldValueAsInt(1))
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
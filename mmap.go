@@ -0,0 +1,93 @@
+package xbase
+
+import "os"
+
+// SetMemoryMap enables memory mapping of files opened by the OpenFile method
+// for reading only. It must be called before opening the file.
+//
+// The record buffer then refers to the mapping, records are not copied
+// and reading them requires no system calls. Mapping is supported on Linux,
+// macOS, the BSDs and Solaris. If the file cannot be mapped, it is read as usual. Records appended to the file after mapping
+// are read by mapping the file again.
+// The file must not be truncated while it is mapped.
+func (db *XBase) SetMemoryMap(on bool) {
+	db.useMmap = on
+}
+
+// MemoryMapped returns true if the file is memory-mapped.
+func (db *XBase) MemoryMapped() bool {
+	return db.mapping != nil
+}
+
+// mapFile maps the opened file into memory.
+// If the file cannot be mapped, it is read as usual.
+func (db *XBase) mapFile() {
+	f, ok := db.file.(*os.File)
+	if !ok {
+		return
+	}
+	fi, err := f.Stat()
+	if err != nil || fi.Size() == 0 {
+		return
+	}
+	if b, err := mmapFile(f, fi.Size()); err == nil {
+		db.mapping = b
+	}
+}
+
+// unmapFile releases the mapping.
+func (db *XBase) unmapFile() {
+	if db.mapping == nil {
+		return
+	}
+	db.ownBuf()
+	munmap(db.mapping)
+	db.mapping = nil
+}
+
+// remap maps the file again if it has grown.
+func (db *XBase) remap() {
+	fi, err := db.file.(*os.File).Stat()
+	if err != nil || fi.Size() <= int64(len(db.mapping)) {
+		return
+	}
+	b, err := mmapFile(db.file.(*os.File), fi.Size())
+	if err != nil {
+		return
+	}
+	db.ownBuf()
+	munmap(db.mapping)
+	db.mapping = b
+}
+
+// readMapped points the record buffer to the current record in the mapping.
+// It returns false if the record is beyond the mapped part of the file.
+func (db *XBase) readMapped() bool {
+	off := db.recOffset()
+	end := off + int64(db.header.RecSize)
+	if end > int64(len(db.mapping)) {
+		db.remap()
+		if end > int64(len(db.mapping)) {
+			return false
+		}
+	}
+	db.buf = db.mapping[off:end:end]
+	db.isMapped = true
+	return true
+}
+
+// ownBuf copies the record from the mapping to the own record buffer,
+// so it can be modified.
+func (db *XBase) ownBuf() {
+	if db.isMapped {
+		copy(db.recBuf, db.buf)
+		db.buf = db.recBuf
+		db.isMapped = false
+	}
+}
+
+// releaseBuf switches to the own record buffer without copying the record.
+func (db *XBase) releaseBuf() {
+	db.buf = db.recBuf
+	db.isMapped = false
+}
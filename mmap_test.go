@@ -0,0 +1,117 @@
+package xbase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryMap(t *testing.T) {
+	if !mmapSupported {
+		t.Skip("memory mapping is not supported")
+	}
+	createCountFile(t, "./testdata/test.dbf", 100)
+
+	db := New()
+	db.SetMemoryMap(true)
+	db.OpenFile("./testdata/test.dbf", true)
+	require.True(t, db.MemoryMapped())
+
+	n := int64(0)
+	for db.First(); !db.EOF(); db.Next() {
+		n++
+		require.Equal(t, n, db.FieldValueAsInt(2))
+	}
+	require.Equal(t, int64(100), n)
+
+	db.GoTo(42)
+	require.True(t, db.isMapped)
+	require.Equal(t, &db.mapping[db.recOffset()], &db.buf[0])
+	require.Equal(t, "Name 42", db.FieldValueAsString(1))
+	name := db.FieldBytes(1)
+	require.False(t, &db.buf[1] == &name[0])
+
+	// changes are made in a copy of the record
+	db.SetFieldValue(1, "Changed")
+	require.False(t, db.isMapped)
+	require.Equal(t, "Changed", db.FieldValueAsString(1))
+	db.Save()
+	require.True(t, errors.Is(db.Error(), ErrReadOnly))
+	db.ResetError()
+	db.Cancel()
+	require.Equal(t, "Name 42", db.FieldValueAsString(1))
+	db.Del()
+	require.True(t, db.RecDeleted())
+	db.Cancel()
+	db.Clear()
+	require.Equal(t, "", db.FieldValueAsString(1))
+	db.Cancel()
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+	require.False(t, db.MemoryMapped())
+	require.Equal(t, "Name 42", string(name[:7]))
+}
+
+func TestMemoryMapAppended(t *testing.T) {
+	if !mmapSupported {
+		t.Skip("memory mapping is not supported")
+	}
+	createCountFile(t, "./testdata/test.dbf", 10)
+
+	db := New()
+	db.SetMemoryMap(true)
+	db.OpenFile("./testdata/test.dbf", true)
+	require.True(t, db.MemoryMapped())
+	size := len(db.mapping)
+
+	w := New()
+	w.OpenFile("./testdata/test.dbf", false)
+	w.Add()
+	w.SetFieldValue(1, "Name 11")
+	w.SetFieldValue(2, 11)
+	w.Save()
+	w.CloseFile()
+	require.NoError(t, w.Error())
+
+	db.header.RecCount++
+	db.GoTo(11)
+	require.Equal(t, "Name 11", db.FieldValueAsString(1))
+	require.Greater(t, len(db.mapping), size)
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestMemoryMapFallback(t *testing.T) {
+	createCountFile(t, "./testdata/test.dbf", 10)
+
+	// files opened for writing are not mapped
+	db := New()
+	db.SetMemoryMap(true)
+	db.OpenFile("./testdata/test.dbf", false)
+	require.False(t, db.MemoryMapped())
+	db.GoTo(3)
+	require.Equal(t, int64(3), db.FieldValueAsInt(2))
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func BenchmarkRandomGoTo(b *testing.B) {
+	name := "./testdata/test_bench.dbf"
+	createCountFile(b, name, 100000)
+	for _, mmap := range []bool{false, true} {
+		b.Run(map[bool]string{false: "File", true: "MemoryMap"}[mmap], func(b *testing.B) {
+			db := New()
+			db.SetMemoryMap(mmap)
+			db.OpenFile(name, true)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				db.GoTo(int64(i*7919%100000 + 1))
+			}
+			b.StopTimer()
+			db.CloseFile()
+			require.NoError(b, db.Error())
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package xbase
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"math"
+)
+
+// OpenReaderAt opens a DBF file stored in r for reading.
+// The size parameter is the size of the file in bytes.
+// If the size is unknown, it can be negative.
+// The CloseFile method does not close r.
+func (db *XBase) OpenReaderAt(r io.ReaderAt, size int64) {
+	if db.err != nil {
+		return
+	}
+	defer db.wrapError("OpenReaderAt")
+	if size < 0 {
+		size = math.MaxInt64
+	}
+	db.file = readOnlyFile{io.NewSectionReader(r, 0, size)}
+	db.name = ""
+	db.isReadOnly = true
+	db.open()
+}
+
+// OpenReadWriteSeeker opens a DBF file stored in rws for reading and writing.
+// The CloseFile method writes the changes to rws but does not close it.
+func (db *XBase) OpenReadWriteSeeker(rws io.ReadWriteSeeker) {
+	if db.err != nil {
+		return
+	}
+	defer db.wrapError("OpenReadWriteSeeker")
+	db.file = streamFile{rws}
+	db.name = ""
+	db.isReadOnly = false
+	db.open()
+}
+
+// OpenFS opens the DBF file with the given name in the file system fsys for reading.
+// It can be used to read files embedded with embed.FS or stored in a zip archive.
+// Files that do not support seeking are read into memory.
+func (db *XBase) OpenFS(fsys fs.FS, name string) {
+	if db.err != nil {
+		return
+	}
+	defer db.wrapError("OpenFS")
+	db.file = fsFile(fsys, name)
+	db.name = ""
+	db.isReadOnly = true
+	db.open()
+}
+
+// fsFile opens the file in the file system.
+func fsFile(fsys fs.FS, name string) Storage {
+	f, err := fsys.Open(name)
+	if err != nil {
+		panic(err)
+	}
+	if rs, ok := f.(io.ReadSeeker); ok {
+		return readOnlyFile{rs}
+	}
+	defer f.Close()
+	b, err := io.ReadAll(f)
+	if err != nil {
+		panic(err)
+	}
+	return readOnlyFile{bytes.NewReader(b)}
+}
+
+// readOnlyFile is a file that cannot be written.
+type readOnlyFile struct {
+	io.ReadSeeker
+}
+
+func (f readOnlyFile) Write(b []byte) (int, error) {
+	return 0, ErrReadOnly
+}
+
+func (f readOnlyFile) Close() error {
+	if c, ok := f.ReadSeeker.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// streamFile hides the Close method of a stream owned by the caller.
+type streamFile struct {
+	io.ReadWriteSeeker
+}
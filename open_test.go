@@ -0,0 +1,112 @@
+package xbase
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func requireRec3(t *testing.T, db *XBase) {
+	require.Equal(t, int64(3), db.RecCount())
+	db.First()
+	require.Equal(t, "Abc", db.FieldValueAsString(1))
+	db.Last()
+	require.Equal(t, "Мышь", db.FieldValueAsString(1))
+	require.Equal(t, -54.32, db.FieldValueAsFloat(4))
+	require.NoError(t, db.Error())
+}
+
+func TestOpenReaderAt(t *testing.T) {
+	b, err := os.ReadFile("./testdata/rec3.dbf")
+	require.NoError(t, err)
+
+	db := New()
+	db.OpenReaderAt(bytes.NewReader(b), int64(len(b)))
+	requireRec3(t, db)
+
+	db.SetFieldValue(1, "Кот")
+	db.Save()
+	require.True(t, errors.Is(db.Error(), ErrReadOnly))
+	db.ResetError()
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+
+	db = New()
+	db.OpenReaderAt(bytes.NewReader(b), -1)
+	requireRec3(t, db)
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestOpenReaderAtNotDBF(t *testing.T) {
+	db := New()
+	db.OpenReaderAt(bytes.NewReader([]byte("not a dbf")), 9)
+	require.Error(t, db.Error())
+}
+
+func TestOpenReadWriteSeeker(t *testing.T) {
+	copyFile("./testdata/rec3.dbf", "./testdata/test.dbf")
+	f, err := os.OpenFile("./testdata/test.dbf", os.O_RDWR, 0666)
+	require.NoError(t, err)
+	defer f.Close()
+
+	db := New()
+	db.OpenReadWriteSeeker(f)
+	requireRec3(t, db)
+	db.Add()
+	db.SetFieldValue(1, "Кот")
+	db.Save()
+	db.CloseFile()
+	require.NoError(t, db.Error())
+
+	// the stream is not closed
+	_, err = f.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+
+	db = New()
+	db.OpenFile("./testdata/test.dbf", true)
+	require.Equal(t, int64(4), db.RecCount())
+	db.Last()
+	require.Equal(t, "Кот", db.FieldValueAsString(1))
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestOpenFS(t *testing.T) {
+	db := New()
+	db.OpenFS(os.DirFS("./testdata"), "rec3.dbf")
+	requireRec3(t, db)
+	db.CloseFile()
+	require.NoError(t, db.Error())
+
+	db = New()
+	db.OpenFS(os.DirFS("./testdata"), "missing.dbf")
+	require.True(t, errors.Is(db.Error(), os.ErrNotExist))
+}
+
+func TestOpenFSZip(t *testing.T) {
+	src, err := os.ReadFile("./testdata/rec3.dbf")
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("data/rec3.dbf")
+	require.NoError(t, err)
+	_, err = w.Write(src)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	db := New()
+	db.OpenFS(zr, "data/rec3.dbf")
+	requireRec3(t, db)
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
@@ -0,0 +1,242 @@
+package xbase
+
+import (
+	"bytes"
+	"strings"
+)
+
+// StringPolicy defines how a character value longer than the field is written.
+type StringPolicy int
+
+// String policies.
+const (
+	StringFail     StringPolicy = iota // the operation fails with ErrValueOverflow
+	StringTruncate                     // the value is truncated on a character boundary
+)
+
+// NumericPolicy defines how a number that does not fit into the field is written.
+type NumericPolicy int
+
+// Numeric policies.
+const (
+	NumericFail  NumericPolicy = iota // the operation fails with ErrValueOverflow
+	NumericStars                      // the field is filled with '*', as dBase does
+	NumericClamp                      // the largest or smallest number that fits is written
+)
+
+// WritePolicy defines how values that do not fit into a field are written.
+// The zero value fails on any overflow.
+type WritePolicy struct {
+	String  StringPolicy
+	Numeric NumericPolicy
+
+	// OnTruncate, if set, is called for every value
+	// that was truncated, filled with '*' or clamped.
+	OnTruncate func(t Truncation)
+}
+
+// Truncation describes a value that was changed to fit into a field.
+type Truncation struct {
+	RecNo   int64  // current record number
+	FieldNo int    // field number
+	Field   string // field name
+	Value   string // value to be written
+	Stored  string // value actually written
+}
+
+// SetWritePolicy sets the write policy for all fields
+// that do not have their own policy.
+func (db *XBase) SetWritePolicy(p WritePolicy) {
+	db.policy = p
+}
+
+// SetFieldWritePolicy sets the write policy for the field.
+// Fields are numbered starting from 1.
+func (db *XBase) SetFieldWritePolicy(fieldNo int, p WritePolicy) {
+	if db.err != nil {
+		return
+	}
+	defer db.wrapFieldError("SetFieldWritePolicy", fieldNo)
+	db.checkFieldNo(fieldNo)
+	if db.fieldPolicies == nil {
+		db.fieldPolicies = make(map[int]*WritePolicy)
+	}
+	db.fieldPolicies[fieldNo] = &p
+}
+
+func (db *XBase) writePolicy(fieldNo int) *WritePolicy {
+	if p, ok := db.fieldPolicies[fieldNo]; ok {
+		return p
+	}
+	return &db.policy
+}
+
+// report passes the truncated value to the callback of the write policy.
+func (db *XBase) report(fieldNo int, t *Truncation) {
+	if t == nil {
+		return
+	}
+	p := db.writePolicy(fieldNo)
+	if p.OnTruncate == nil {
+		return
+	}
+	t.RecNo = db.recNo
+	if db.isAdd {
+		t.RecNo = db.recCount() + 1
+	}
+	t.FieldNo = fieldNo
+	t.Field = db.fields[fieldNo-1].name()
+	p.OnTruncate(*t)
+}
+
+// Cleanup is a set of rules to clean up invalid values in lenient read mode.
+type Cleanup int
+
+// Cleanup rules.
+const (
+	CleanNUL     Cleanup = 1 << iota // NUL bytes are read as spaces
+	CleanComma                       // a decimal comma is read as a point
+	CleanStars                       // a numeric value of '*' overflow markers is read as null
+	CleanLogical                     // '1' and '0' are read as true and false
+
+	CleanAll = CleanNUL | CleanComma | CleanStars | CleanLogical
+)
+
+// ReadPolicy defines how invalid numeric and logical values are read.
+// The zero value fails on any invalid value.
+type ReadPolicy struct {
+	// Lenient enables lenient read mode.
+	// Values are cleaned up by the Cleanup rules,
+	// values that still cannot be parsed are read as null (zero) values.
+	Lenient bool
+	Cleanup Cleanup
+
+	// OnDiagnostic, if set, is called for every value
+	// that was cleaned up or could not be parsed in lenient read mode.
+	OnDiagnostic func(d Diagnostic)
+}
+
+// Diagnostic describes an invalid value found in lenient read mode.
+type Diagnostic struct {
+	RecNo   int64  // current record number
+	FieldNo int    // field number
+	Field   string // field name
+	Raw     string // raw text of the field
+	Message string // what was wrong with the value
+}
+
+// SetReadPolicy sets the read policy.
+func (db *XBase) SetReadPolicy(p ReadPolicy) {
+	db.readPolicy = p
+}
+
+// readField returns the field and the record buffer with the field value
+// cleaned up according to the read policy.
+// If the value cannot be parsed, ok is false and the value must be read as null.
+func (db *XBase) readField(fieldNo int, typ byte) (f *field, recordBuf []byte, ok bool) {
+	f = db.fieldByNo(fieldNo)
+	f.checkType(typ)
+	recordBuf, ok = db.cleanField(fieldNo, f, db.buf)
+	return f, recordBuf, ok
+}
+
+// cleanField cleans up the field value in lenient read mode.
+// The record buffer is copied if the value is changed.
+func (db *XBase) cleanField(fieldNo int, f *field, recordBuf []byte) ([]byte, bool) {
+	p := &db.readPolicy
+	if !p.Lenient || (f.Type != 'N' && f.Type != 'L') {
+		return recordBuf, true
+	}
+	raw := f.buffer(recordBuf)
+	b, msgs := f.clean(raw, p.Cleanup)
+	ok := f.valid(b)
+	if !ok {
+		msgs = append(msgs, "invalid "+typeName(f.Type)+" value")
+	}
+	if len(msgs) == 0 {
+		return recordBuf, true
+	}
+	if p.OnDiagnostic != nil {
+		p.OnDiagnostic(Diagnostic{
+			RecNo:   db.recNo,
+			FieldNo: fieldNo,
+			Field:   f.name(),
+			Raw:     string(raw),
+			Message: strings.Join(msgs, ", "),
+		})
+	}
+	buf := append([]byte(nil), recordBuf...)
+	copy(f.buffer(buf), b)
+	return buf, ok
+}
+
+// clean returns a copy of the raw field value cleaned up by the rules
+// and the list of the changes made.
+func (f *field) clean(raw []byte, rules Cleanup) ([]byte, []string) {
+	b := append([]byte(nil), raw...)
+	var msgs []string
+	replace := func(rule Cleanup, from, to byte, msg string) {
+		if rules&rule == 0 || bytes.IndexByte(b, from) < 0 {
+			return
+		}
+		for i := range b {
+			if b[i] == from {
+				b[i] = to
+			}
+		}
+		msgs = append(msgs, msg)
+	}
+	replace(CleanNUL, 0, ' ', "NUL bytes")
+	switch f.Type {
+	case 'N':
+		replace(CleanComma, ',', '.', "decimal comma")
+		s := strings.TrimSpace(string(b))
+		if rules&CleanStars != 0 && s != "" && strings.Trim(s, "*") == "" {
+			b = bytes.Repeat([]byte{' '}, len(b))
+			msgs = append(msgs, "overflow marker")
+		}
+	case 'L':
+		replace(CleanLogical, '1', 'T', "logical 1")
+		replace(CleanLogical, '0', 'F', "logical 0")
+	}
+	return b, msgs
+}
+
+// valid reports whether the raw numeric or logical value can be parsed.
+func (f *field) valid(raw []byte) bool {
+	switch f.Type {
+	case 'N':
+		s := strings.TrimSpace(string(raw))
+		return s == "" || isNumber(s)
+	case 'L':
+		return bytes.IndexByte([]byte("TtYyFfNn? "), raw[0]) >= 0
+	}
+	return true
+}
+
+// isNumber reports whether s is a decimal number with an optional sign.
+func isNumber(s string) bool {
+	if s[0] == '-' || s[0] == '+' {
+		s = s[1:]
+	}
+	digits := false
+	point := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] >= '0' && s[i] <= '9':
+			digits = true
+		case s[i] == '.' && !point:
+			point = true
+		default:
+			return false
+		}
+	}
+	return digits
+}
+
+func typeName(t byte) string {
+	if t == 'N' {
+		return "numeric"
+	}
+	return "logical"
+}
@@ -0,0 +1,216 @@
+package xbase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldOverflowNumber(t *testing.T) {
+	f := newField("NAME", "N", 6, 2)
+	require.Equal(t, "******", f.overflowNumber(false, NumericStars))
+	require.Equal(t, "999.99", f.overflowNumber(false, NumericClamp))
+	require.Equal(t, "-99.99", f.overflowNumber(true, NumericClamp))
+
+	f = newField("NAME", "N", 3, 0)
+	require.Equal(t, "999", f.overflowNumber(false, NumericClamp))
+	require.Equal(t, "-99", f.overflowNumber(true, NumericClamp))
+
+	f = newField("NAME", "N", 1, 0)
+	require.Equal(t, "9", f.overflowNumber(false, NumericClamp))
+	require.Equal(t, "0", f.overflowNumber(true, NumericClamp))
+}
+
+func TestClampWidthOne(t *testing.T) {
+	db := New()
+	db.AddField("FLAG", "N", 1)
+	db.CreateFile("./testdata/test.dbf")
+	db.SetWritePolicy(WritePolicy{Numeric: NumericClamp})
+
+	db.Add()
+	db.SetFieldValue(1, -5)
+	require.Equal(t, float64(0), db.FieldValueAsFloat(1))
+	db.SetFieldValue(1, -0.5)
+	require.Equal(t, int64(0), db.FieldValueAsInt(1))
+	db.SetFieldValue(1, 12)
+	require.Equal(t, int64(9), db.FieldValueAsInt(1))
+	require.NoError(t, db.Error())
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestFieldSetStringValueTruncate(t *testing.T) {
+	recordBuf := make([]byte, 10)
+	f := newField("NAME", "C", 5, 0)
+	f.Offset = 1
+
+	wp := &WritePolicy{String: StringTruncate}
+	tr := f.setStringValue(recordBuf, "Abcdefg", nil, wp)
+	require.Equal(t, []byte("Abcde"), recordBuf[1:6])
+	require.Equal(t, &Truncation{Value: "Abcdefg", Stored: "Abcde"}, tr)
+
+	tr = f.setStringValue(recordBuf, "Мышка", nil, wp)
+	require.Equal(t, []byte("Мы "), recordBuf[1:6])
+	require.Equal(t, "Мы", tr.Stored)
+
+	require.Nil(t, f.setStringValue(recordBuf, "Abc", nil, wp))
+	require.Panics(t, func() { f.setStringValue(recordBuf, "Abcdefg", nil, nil) })
+}
+
+func TestWritePolicy(t *testing.T) {
+	var truncs []Truncation
+
+	db := New()
+	addFields(db)
+	db.SetWritePolicy(WritePolicy{
+		String:     StringTruncate,
+		Numeric:    NumericStars,
+		OnTruncate: func(t Truncation) { truncs = append(truncs, t) },
+	})
+	db.CreateFile("./testdata/test.dbf")
+
+	db.Add()
+	db.SetFieldValue(1, "Длинное имя, которое не помещается")
+	db.SetFieldValue(3, 123456)
+	db.SetFieldValue(4, -1234567.5)
+	db.Save()
+	require.NoError(t, db.Error())
+
+	require.Equal(t, "Длинное имя, которое", db.FieldValueAsString(1))
+	require.Equal(t, "*****", db.FieldValueAsString(3))
+	require.Equal(t, "*********", db.FieldValueAsString(4))
+
+	require.Len(t, truncs, 3)
+	require.Equal(t, Truncation{RecNo: 1, FieldNo: 1, Field: "NAME", Value: "Длинное имя, которое не помещается", Stored: "Длинное имя, которое"}, truncs[0])
+	require.Equal(t, Truncation{RecNo: 1, FieldNo: 3, Field: "COUNT", Value: "123456", Stored: "*****"}, truncs[1])
+	require.Equal(t, Truncation{RecNo: 1, FieldNo: 4, Field: "PRICE", Value: "-1234567.50", Stored: "*********"}, truncs[2])
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestFieldWritePolicy(t *testing.T) {
+	db := New()
+	addFields(db)
+	db.SetFieldWritePolicy(4, WritePolicy{Numeric: NumericClamp})
+	db.CreateFile("./testdata/test.dbf")
+
+	db.Add()
+	db.SetFieldValue(4, 1e9)
+	require.Equal(t, "999999.99", db.FieldValueAsString(4))
+	db.SetFieldValue(4, -1e9)
+	require.Equal(t, "-99999.99", db.FieldValueAsString(4))
+	require.NoError(t, db.Error())
+
+	db.SetFieldValue(3, 123456)
+	require.True(t, errors.Is(db.Error(), ErrValueOverflow))
+	db.ResetError()
+
+	db.SetFieldWritePolicy(6, WritePolicy{})
+	require.True(t, errors.Is(db.Error(), ErrFieldOutOfRange))
+	db.ResetError()
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestFieldClean(t *testing.T) {
+	f := newField("NAME", "N", 8, 2)
+	b, msgs := f.clean([]byte("  12,50\x00"), CleanAll)
+	require.Equal(t, []byte("  12.50 "), b)
+	require.Equal(t, []string{"NUL bytes", "decimal comma"}, msgs)
+
+	b, msgs = f.clean([]byte("********"), CleanAll)
+	require.Equal(t, []byte("        "), b)
+	require.Equal(t, []string{"overflow marker"}, msgs)
+
+	b, msgs = f.clean([]byte("  12,50 "), CleanNUL)
+	require.Equal(t, []byte("  12,50 "), b)
+	require.Nil(t, msgs)
+
+	f = newField("FLAG", "L", 1, 0)
+	b, msgs = f.clean([]byte("1"), CleanAll)
+	require.Equal(t, []byte("T"), b)
+	require.Equal(t, []string{"logical 1"}, msgs)
+}
+
+func TestFieldValid(t *testing.T) {
+	f := newField("NAME", "N", 8, 2)
+	require.True(t, f.valid([]byte("   12.50")))
+	require.True(t, f.valid([]byte("-0012.50")))
+	require.True(t, f.valid([]byte("        ")))
+	require.False(t, f.valid([]byte("   12,50")))
+	require.False(t, f.valid([]byte("      - ")))
+	require.False(t, f.valid([]byte("********")))
+
+	f = newField("FLAG", "L", 1, 0)
+	require.True(t, f.valid([]byte("n")))
+	require.True(t, f.valid([]byte("?")))
+	require.False(t, f.valid([]byte("0")))
+	require.False(t, f.valid([]byte{0}))
+}
+
+func TestReadPolicy(t *testing.T) {
+	db := New()
+	addFields(db)
+	db.CreateFile("./testdata/test.dbf")
+	db.Add()
+	db.SetFieldValue(1, "Abc")
+	db.SetFieldBytes(2, []byte("0"))
+	db.SetFieldBytes(3, []byte("*****"))
+	db.SetFieldBytes(4, []byte("  123,45\x00"))
+	db.Save()
+	require.NoError(t, db.Error())
+
+	db.FieldValueAsFloat(4)
+	require.Error(t, db.Error())
+	db.ResetError()
+
+	var diags []Diagnostic
+	db.SetReadPolicy(ReadPolicy{
+		Lenient:      true,
+		Cleanup:      CleanAll,
+		OnDiagnostic: func(d Diagnostic) { diags = append(diags, d) },
+	})
+	require.Equal(t, false, db.FieldValueAsBool(2))
+	require.Equal(t, int64(0), db.FieldValueAsInt(3))
+	require.Equal(t, 123.45, db.FieldValueAsFloat(4))
+	requireRat(t, "123.45", db.FieldValueAsDecimal(4))
+	require.NoError(t, db.Error())
+	require.Equal(t, Diagnostic{RecNo: 1, FieldNo: 4, Field: "PRICE", Raw: "  123,45\x00", Message: "NUL bytes, decimal comma"}, diags[2])
+	require.Equal(t, "overflow marker", diags[1].Message)
+
+	diags = nil
+	r := db.Record()
+	require.NoError(t, db.Error())
+	require.Len(t, diags, 3)
+	require.Equal(t, "Abc", r.Get("NAME").String())
+	require.False(t, r.Get("FLAG").Null)
+	require.True(t, r.Get("COUNT").Null)
+	require.Equal(t, []byte("*****"), r.Get("COUNT").Raw)
+	require.Equal(t, 123.45, r.Get("PRICE").Float())
+	require.Equal(t, "123.45 ", r.Get("PRICE").String())
+	require.Equal(t, []byte("  123,45\x00"), r.Get("PRICE").Raw)
+
+	// without cleanup rules invalid values are read as null
+	diags = nil
+	db.SetReadPolicy(ReadPolicy{
+		Lenient:      true,
+		OnDiagnostic: func(d Diagnostic) { diags = append(diags, d) },
+	})
+	require.Equal(t, 0.0, db.FieldValueAsFloat(4))
+	r = db.Record()
+	require.NoError(t, db.Error())
+	require.True(t, r.Get("PRICE").Null)
+	require.Equal(t, "invalid numeric value", diags[0].Message)
+
+	db.SetFieldBytes(4, []byte("      +.5"))
+	require.Equal(t, int64(0), db.FieldValueAsInt(4))
+	require.Equal(t, 0.5, db.FieldValueAsFloat(4))
+	require.NoError(t, db.Error())
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
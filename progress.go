@@ -0,0 +1,47 @@
+package xbase
+
+import "context"
+
+// Progress describes the progress of an operation over all records of a file.
+type Progress struct {
+	Records int64 // number of records processed
+	Total   int64 // total number of records
+	Bytes   int64 // size of the records processed in bytes
+}
+
+// ProgressFunc is called after each record processed
+// by an operation over all records of a file.
+type ProgressFunc func(p Progress)
+
+// tracker checks the context and reports the progress of an operation.
+type tracker struct {
+	ctx      context.Context
+	fn       ProgressFunc
+	progress Progress
+	recSize  int64
+}
+
+func (db *XBase) newTracker(ctx context.Context, fn ProgressFunc) *tracker {
+	t := &tracker{ctx: ctx, fn: fn}
+	t.progress.Total = db.recCount()
+	t.recSize = int64(db.header.RecSize)
+	t.check()
+	return t
+}
+
+// check panics if the context is canceled or its deadline is exceeded.
+func (t *tracker) check() {
+	if err := t.ctx.Err(); err != nil {
+		panic(err)
+	}
+}
+
+// step counts a processed record.
+func (t *tracker) step() {
+	t.progress.Records++
+	t.progress.Bytes += t.recSize
+	if t.fn != nil {
+		t.fn(t.progress)
+	}
+	t.check()
+}
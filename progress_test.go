@@ -0,0 +1,142 @@
+package xbase
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadAllContext(t *testing.T) {
+	db := New()
+	db.OpenFile("./testdata/rec3.dbf", true)
+
+	var progress []Progress
+	var recs []testRec
+	db.ReadAllContext(context.Background(), &recs, func(p Progress) { progress = append(progress, p) })
+	require.NoError(t, db.Error())
+	require.Len(t, recs, 3)
+	recSize := int64(db.header.RecSize)
+	require.Equal(t, []Progress{
+		{Records: 1, Total: 3, Bytes: recSize},
+		{Records: 2, Total: 3, Bytes: 2 * recSize},
+		{Records: 3, Total: 3, Bytes: 3 * recSize},
+	}, progress)
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestReadAllContextCanceled(t *testing.T) {
+	db := New()
+	db.OpenFile("./testdata/rec3.dbf", true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var recs []testRec
+	db.ReadAllContext(ctx, &recs, func(p Progress) {
+		if p.Records == 2 {
+			cancel()
+		}
+	})
+	require.True(t, errors.Is(db.Error(), context.Canceled))
+	var e *Error
+	require.True(t, errors.As(db.Error(), &e))
+	require.Equal(t, "ReadAllContext", e.Op)
+	require.Len(t, recs, 2)
+	db.ResetError()
+
+	db.ReadAllContext(ctx, &recs, nil)
+	require.True(t, errors.Is(db.Error(), context.Canceled))
+	require.Len(t, recs, 0)
+	db.ResetError()
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestTranscodeContext(t *testing.T) {
+	copyFile("./testdata/rec3.dbf", "./testdata/test.dbf")
+	db := New()
+	db.OpenFile("./testdata/test.dbf", false)
+
+	var last Progress
+	issues := db.TranscodeContext(context.Background(), 1251, func(p Progress) { last = p })
+	require.Empty(t, issues)
+	require.NoError(t, db.Error())
+	require.Equal(t, int64(3), last.Records)
+	require.Equal(t, 1251, db.CodePage())
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestTranscodeToContextCanceled(t *testing.T) {
+	db := New()
+	db.OpenFile("./testdata/rec3.dbf", true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	db.TranscodeToContext(ctx, "./testdata/test_cp.dbf", 1251, nil)
+	require.True(t, errors.Is(db.Error(), context.Canceled))
+	db.ResetError()
+
+	os.Remove("./testdata/test_cp.dbf")
+	ctx, cancel = context.WithCancel(context.Background())
+	db.TranscodeToContext(ctx, "./testdata/test_cp.dbf", 1251, func(p Progress) { cancel() })
+	require.True(t, errors.Is(db.Error(), context.Canceled))
+	db.ResetError()
+	_, err := os.Stat("./testdata/test_cp.dbf")
+	require.True(t, os.IsNotExist(err))
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestTranscodeContextCanceled(t *testing.T) {
+	copyFile("./testdata/rec3.dbf", "./testdata/test.dbf")
+	db := New()
+	db.OpenFile("./testdata/test.dbf", false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	db.TranscodeContext(ctx, 1251, nil)
+	require.True(t, errors.Is(db.Error(), context.Canceled))
+	db.ResetError()
+	require.Equal(t, 866, db.CodePage())
+
+	// once started, the conversion is not stopped halfway
+	ctx, cancel = context.WithCancel(context.Background())
+	var last Progress
+	db.TranscodeContext(ctx, 1251, func(p Progress) { last = p; cancel() })
+	require.NoError(t, db.Error())
+	require.Equal(t, int64(3), last.Records)
+	require.Equal(t, 1251, db.CodePage())
+	db.GoTo(3)
+	require.Equal(t, "Мышь", db.FieldValueAsString(1))
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestTranscodeContextCanceledAtomic(t *testing.T) {
+	copyFile("./testdata/rec3.dbf", "./testdata/test.dbf")
+	db := New()
+	db.SetDurability(Durability{AtomicRewrite: true})
+	db.OpenFile("./testdata/test.dbf", false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	db.TranscodeContext(ctx, 1251, func(p Progress) { cancel() })
+	require.True(t, errors.Is(db.Error(), context.Canceled))
+	db.ResetError()
+	require.Equal(t, 866, db.CodePage())
+	db.GoTo(3)
+	require.Equal(t, "Мышь", db.FieldValueAsString(1))
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+	require.Equal(t, readFile("./testdata/rec3.dbf"), readFile("./testdata/test.dbf"))
+	tmp, _ := filepath.Glob("./testdata/test.dbf.*.tmp")
+	require.Empty(t, tmp)
+}
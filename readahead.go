@@ -0,0 +1,86 @@
+package xbase
+
+import "io"
+
+// defaultReadAhead is the default size of the read-ahead block.
+const defaultReadAhead = 1 << 20
+
+// readCache holds a block of records read ahead on sequential access.
+type readCache struct {
+	size  int    // block size in bytes, 0 disables reading ahead
+	buf   []byte // records read ahead
+	first int64  // number of the first record in buf
+	count int64  // number of records in buf
+	last  int64  // number of the last record read, -1 if none
+}
+
+// SetReadAhead sets the size in bytes of the block of records
+// read at once when the records are read sequentially.
+// The default size is 1 MB, 0 disables reading ahead.
+//
+// Changes made through the object are visible in the block,
+// changes made to the file by other programs are not visible until Refresh.
+func (db *XBase) SetReadAhead(size int) {
+	if size < 0 {
+		size = 0
+	}
+	db.cache.size = size
+	db.cache.reset()
+	db.cache.buf = nil
+}
+
+func (c *readCache) reset() {
+	c.count = 0
+	c.last = -1
+}
+
+// readCached copies the current record from the read-ahead block.
+// On sequential access the block is filled with the following records.
+// It returns false if the record must be read from the file.
+func (db *XBase) readCached() bool {
+	c := &db.cache
+	defer func() { c.last = db.recNo }()
+	recSize := int64(db.header.RecSize)
+	if db.recNo >= c.first && db.recNo < c.first+c.count {
+		copy(db.buf, c.buf[(db.recNo-c.first)*recSize:])
+		return true
+	}
+	if db.recNo != c.last+1 {
+		return false
+	}
+	n := int64(c.size) / recSize
+	if rest := db.recCount() - db.recNo + 1; n > rest {
+		n = rest
+	}
+	if n < 2 {
+		return false
+	}
+	if int64(cap(c.buf)) < n*recSize {
+		c.buf = make([]byte, n*recSize)
+	}
+	c.count = 0
+	c.buf = c.buf[:n*recSize]
+	db.seekRec()
+	db.checkFile()
+	read, err := io.ReadFull(db.file, c.buf)
+	if err == io.ErrUnexpectedEOF || err == io.EOF {
+		// The file is truncated, only the complete records are kept.
+		n = int64(read) / recSize
+	} else if err != nil {
+		panic(err)
+	}
+	if n == 0 {
+		return false
+	}
+	c.first, c.count = db.recNo, n
+	copy(db.buf, c.buf)
+	return true
+}
+
+// writeCached updates the current record in the read-ahead block.
+func (db *XBase) writeCached() {
+	c := &db.cache
+	if db.recNo >= c.first && db.recNo < c.first+c.count {
+		copy(c.buf[(db.recNo-c.first)*int64(db.header.RecSize):], db.buf)
+	}
+}
@@ -0,0 +1,125 @@
+package xbase
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createCountFile(t testing.TB, name string, count int) {
+	db := New()
+	db.AddField("NAME", "C", 30)
+	db.AddField("COUNT", "N", 10)
+	db.CreateFile(name)
+	for i := 1; i <= count; i++ {
+		db.Add()
+		db.SetFieldValue(1, fmt.Sprintf("Name %d", i))
+		db.SetFieldValue(2, i)
+		db.Save()
+	}
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestReadAhead(t *testing.T) {
+	createCountFile(t, "./testdata/test.dbf", 100)
+
+	db := New()
+	db.SetReadAhead(10 * 41)
+	db.OpenFile("./testdata/test.dbf", false)
+	db.First()
+	require.Equal(t, int64(0), db.cache.count)
+	db.Next()
+	require.Equal(t, int64(2), db.cache.first)
+	require.Equal(t, int64(10), db.cache.count)
+
+	// a saved record is updated in the block
+	db.GoTo(5)
+	db.SetFieldValue(2, -5)
+	db.Save()
+	db.GoTo(4)
+	db.Next()
+	require.Equal(t, int64(-5), db.FieldValueAsInt(2))
+
+	var sum int64
+	for db.First(); !db.EOF(); db.Next() {
+		sum += db.FieldValueAsInt(2)
+	}
+	require.Equal(t, int64(100*101/2-10), sum)
+	require.Equal(t, int64(92), db.cache.first)
+	require.Equal(t, int64(9), db.cache.count)
+
+	// random access does not fill the block
+	db.SetReadAhead(10 * 41)
+	db.GoTo(50)
+	db.GoTo(20)
+	require.Equal(t, int64(0), db.cache.count)
+	require.Equal(t, "Name 20", db.FieldValueAsString(1))
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestReadAheadDisabled(t *testing.T) {
+	createCountFile(t, "./testdata/test.dbf", 10)
+
+	db := New()
+	db.SetReadAhead(0)
+	db.OpenFile("./testdata/test.dbf", true)
+	n := 0
+	for db.First(); !db.EOF(); db.Next() {
+		n++
+		require.Equal(t, int64(n), db.FieldValueAsInt(2))
+	}
+	require.Equal(t, 10, n)
+	require.Nil(t, db.cache.buf)
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestReadAheadTruncated(t *testing.T) {
+	createCountFile(t, "./testdata/test.dbf", 100)
+	fi, err := os.Stat("./testdata/test.dbf")
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate("./testdata/test.dbf", fi.Size()-10))
+
+	for _, size := range []int{0, defaultReadAhead, 10 * 41} {
+		db := New()
+		db.SetReadAhead(size)
+		db.OpenFile("./testdata/test.dbf", true)
+		n := int64(0)
+		for db.First(); !db.EOF(); db.Next() {
+			n++
+			require.Equal(t, n, db.FieldValueAsInt(2))
+		}
+		require.Equal(t, int64(99), n)
+		require.True(t, errors.Is(db.Error(), io.ErrUnexpectedEOF))
+		db.ResetError()
+		db.CloseFile()
+		require.NoError(t, db.Error())
+	}
+}
+
+func BenchmarkScan(b *testing.B) {
+	name := "./testdata/test_bench.dbf"
+	createCountFile(b, name, 100000)
+	for _, size := range []int{0, 64 << 10, 1 << 20} {
+		b.Run(fmt.Sprintf("ReadAhead%d", size), func(b *testing.B) {
+			db := New()
+			db.SetReadAhead(size)
+			db.OpenFile(name, true)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for db.First(); !db.EOF(); db.Next() {
+				}
+			}
+			b.StopTimer()
+			db.CloseFile()
+			require.NoError(b, db.Error())
+		})
+	}
+}
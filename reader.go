@@ -0,0 +1,124 @@
+package xbase
+
+import (
+	"bufio"
+	"io"
+	"time"
+)
+
+// Reader reads the records of a DBF file sequentially
+// from an io.Reader that does not support seeking,
+// such as a gzip stream or the standard input.
+type Reader struct {
+	db  *XBase
+	r   *bufio.Reader
+	err error
+}
+
+// NewReader returns a Reader that reads from r.
+// It reads the header and the field descriptors of the file.
+func NewReader(r io.Reader) (_ *Reader, err error) {
+	rd := &Reader{db: New(), r: bufio.NewReader(r)}
+	defer catchError("NewReader", &err)
+	rd.readHeader()
+	return rd, nil
+}
+
+// Schema returns the structure of the file.
+func (r *Reader) Schema() Schema {
+	return schemaOf(r.db.fields)
+}
+
+// CodePage returns the code page of the file.
+// Returns 0 if no code page is specified.
+func (r *Reader) CodePage() int {
+	return r.db.CodePage()
+}
+
+// SetCodePage sets the code page for decoding string field values.
+// It can be used for files without a code page mark.
+func (r *Reader) SetCodePage(cp int) {
+	r.db.SetCodePage(cp)
+}
+
+// RecCount returns the number of records stated in the header.
+func (r *Reader) RecCount() int64 {
+	return r.db.recCount()
+}
+
+// ModDate returns the modification date of the file.
+func (r *Reader) ModDate() time.Time {
+	return r.db.ModDate()
+}
+
+// SetReadPolicy sets the read policy.
+// See XBase.SetReadPolicy for details.
+func (r *Reader) SetReadPolicy(p ReadPolicy) {
+	r.db.SetReadPolicy(p)
+}
+
+// SetLocation sets the location of date field values.
+// See XBase.SetLocation for details.
+func (r *Reader) SetLocation(loc *time.Location) {
+	r.db.SetLocation(loc)
+}
+
+// Read reads the next record, including records marked as deleted.
+// At the end of the file it returns io.EOF.
+//
+// An error reading the input is returned by all following calls.
+// An error decoding a field value only affects the current record.
+func (r *Reader) Read() (rec Record, err error) {
+	if r.err == nil {
+		r.err = r.next()
+	}
+	if r.err != nil {
+		return Record{}, r.err
+	}
+	defer r.catchError("Read", &err)
+	return r.db.record(), nil
+}
+
+// readHeader reads the header and the field descriptors
+// and skips the rest of the header.
+func (r *Reader) readHeader() {
+	db := r.db
+	db.header.read(r.r)
+	db.readFields(r.r)
+	n := int64(db.header.DataOffset) - headerSize - int64(len(db.fields))*fieldSize
+	if n < 1 {
+		panic(ErrNotDBF)
+	}
+	if _, err := r.r.Discard(int(n)); err != nil {
+		panic(err)
+	}
+	db.makeBuf()
+	db.SetCodePage(db.CodePage())
+}
+
+// next reads the next record into the record buffer.
+func (r *Reader) next() (err error) {
+	db := r.db
+	if db.recNo >= db.recCount() {
+		return io.EOF
+	}
+	defer r.catchError("Read", &err)
+	db.recNo++
+	if _, err := io.ReadFull(r.r, db.buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		panic(err)
+	}
+	return nil
+}
+
+// catchError converts a panic to an error with the current record number.
+// It must be deferred.
+func (r *Reader) catchError(op string, err *error) {
+	if p := recover(); p != nil {
+		r.db.setError(&Error{Op: op}, p)
+		*err = r.db.err
+		r.db.err = nil
+	}
+}
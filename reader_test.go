@@ -0,0 +1,113 @@
+package xbase
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReader(t *testing.T) {
+	b, err := os.ReadFile("./testdata/rec3.dbf")
+	require.NoError(t, err)
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	_, err = zw.Write(b)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	zr, err := gzip.NewReader(&gz)
+	require.NoError(t, err)
+	r, err := NewReader(zr)
+	require.NoError(t, err)
+	require.Equal(t, 866, r.CodePage())
+	require.Equal(t, int64(3), r.RecCount())
+	require.Equal(t, Schema{
+		{Name: "NAME", Type: "C", Len: 20},
+		{Name: "FLAG", Type: "L", Len: 1},
+		{Name: "COUNT", Type: "N", Len: 5},
+		{Name: "PRICE", Type: "N", Len: 9, Dec: 2},
+		{Name: "DATE", Type: "D", Len: 8},
+	}, r.Schema())
+
+	rec, err := r.Read()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rec.RecNo)
+	require.Equal(t, "Abc", rec.Get("NAME").String())
+	require.Equal(t, int64(123), rec.Get("COUNT").Int())
+
+	rec, err = r.Read()
+	require.NoError(t, err)
+	require.True(t, rec.Get("COUNT").Null)
+
+	rec, err = r.Read()
+	require.NoError(t, err)
+	require.Equal(t, int64(3), rec.RecNo)
+	require.Equal(t, "Мышь", rec.Get("NAME").String())
+	require.Equal(t, -54.32, rec.Get("PRICE").Float())
+
+	_, err = r.Read()
+	require.Equal(t, io.EOF, err)
+	_, err = r.Read()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestReaderTruncated(t *testing.T) {
+	b, err := os.ReadFile("./testdata/rec3.dbf")
+	require.NoError(t, err)
+
+	r, err := NewReader(bytes.NewReader(b[:len(b)-20]))
+	require.NoError(t, err)
+	_, err = r.Read()
+	require.NoError(t, err)
+	_, err = r.Read()
+	require.NoError(t, err)
+	_, err = r.Read()
+	require.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	require.Equal(t, int64(3), e.RecNo)
+	_, err2 := r.Read()
+	require.Equal(t, err, err2)
+}
+
+func TestReaderNotDBF(t *testing.T) {
+	_, err := NewReader(bytes.NewReader(bytes.Repeat([]byte{0x01}, 100)))
+	require.True(t, errors.Is(err, ErrNotDBF))
+	_, err = NewReader(bytes.NewReader(nil))
+	require.True(t, errors.Is(err, io.EOF))
+}
+
+func TestReaderInvalidValue(t *testing.T) {
+	db := New()
+	addFields(db)
+	db.CreateFile("./testdata/test.dbf")
+	db.Add()
+	db.SetFieldBytes(3, []byte("1,5"))
+	db.Save()
+	db.Add()
+	db.SetFieldValue(3, 7)
+	db.Save()
+	db.CloseFile()
+	require.NoError(t, db.Error())
+
+	f, err := os.Open("./testdata/test.dbf")
+	require.NoError(t, err)
+	defer f.Close()
+	r, err := NewReader(f)
+	require.NoError(t, err)
+
+	_, err = r.Read()
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	require.Equal(t, "COUNT", e.Field)
+	require.Equal(t, int64(1), e.RecNo)
+
+	rec, err := r.Read()
+	require.NoError(t, err)
+	require.Equal(t, int64(7), rec.Get("COUNT").Int())
+}
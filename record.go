@@ -0,0 +1,262 @@
+package xbase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding"
+)
+
+// Kind is the kind of a field value. It matches the field type.
+type Kind byte
+
+// Value kinds.
+const (
+	KindCharacter Kind = 'C'
+	KindNumeric   Kind = 'N'
+	KindLogical   Kind = 'L'
+	KindDate      Kind = 'D'
+)
+
+func (k Kind) String() string {
+	return string([]byte{byte(k)})
+}
+
+// Value is a decoded field value.
+//
+// Numeric, logical and date values consisting of spaces are null.
+// Character values are never null, an empty string is returned for them.
+// In lenient read mode the String method returns the cleaned up text,
+// while Raw holds the original bytes.
+type Value struct {
+	Kind Kind   // kind of the value
+	Null bool   // the value is empty
+	Raw  []byte // raw bytes of the field
+
+	str string
+	v   interface{}
+}
+
+// Interface returns the value as string, int64 (numeric field without decimals),
+// float64 (numeric field with decimals), bool or time.Time.
+// It returns nil for a null value.
+func (v Value) Interface() interface{} {
+	if v.Null {
+		return nil
+	}
+	return v.v
+}
+
+// String returns the value as string, as the FieldValueAsString method does.
+func (v Value) String() string {
+	return v.str
+}
+
+// Int returns a numeric value as integer.
+// The fractional part is discarded.
+func (v Value) Int() int64 {
+	switch n := v.v.(type) {
+	case int64:
+		return n
+	case float64:
+		return int64(n)
+	}
+	return 0
+}
+
+// Float returns a numeric value as float.
+func (v Value) Float() float64 {
+	switch n := v.v.(type) {
+	case int64:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
+
+// Bool returns a logical value.
+func (v Value) Bool() bool {
+	b, _ := v.v.(bool)
+	return b
+}
+
+// Time returns a date value.
+func (v Value) Time() time.Time {
+	d, _ := v.v.(time.Time)
+	return d
+}
+
+// Record holds the values of all fields of a record.
+// It is a snapshot and does not change when the current record changes.
+type Record struct {
+	RecNo   int64 // record number
+	Deleted bool  // the record is marked as deleted
+
+	fields []*field
+	index  map[string]int
+	values []Value
+}
+
+// Len returns the number of values in the record.
+func (r Record) Len() int {
+	return len(r.values)
+}
+
+// Value returns the value of the field by number.
+// Fields are numbered starting from 1.
+// For a field number out of range it returns the zero Value.
+func (r Record) Value(fieldNo int) Value {
+	if fieldNo < 1 || fieldNo > len(r.values) {
+		return Value{}
+	}
+	return r.values[fieldNo-1]
+}
+
+// Get returns the value of the field by name.
+// If name is not found returns the zero Value with zero Kind.
+func (r Record) Get(name string) Value {
+	return r.Value(r.index[normName(name)])
+}
+
+// Names returns the field names in the order of the fields.
+func (r Record) Names() []string {
+	names := make([]string, len(r.fields))
+	for i, f := range r.fields {
+		names[i] = f.name()
+	}
+	return names
+}
+
+// Map returns the field values by field names.
+// Null values are represented by nil.
+func (r Record) Map() map[string]interface{} {
+	m := make(map[string]interface{}, len(r.values))
+	for i, f := range r.fields {
+		m[f.name()] = r.values[i].Interface()
+	}
+	return m
+}
+
+// Record returns the values of all fields of the current record.
+func (db *XBase) Record() Record {
+	if db.err != nil {
+		return Record{}
+	}
+	defer db.wrapError("Record")
+	return db.record()
+}
+
+// SetRecord sets the field values of the current record by field names.
+// A nil value clears the field. Fields missing in the map are not changed.
+// To save the changes, you need to call the Save method.
+func (db *XBase) SetRecord(values map[string]interface{}) {
+	if db.err != nil {
+		return
+	}
+	defer db.wrapError("SetRecord")
+	db.setRecord(values)
+}
+
+func (db *XBase) setRecord(values map[string]interface{}) {
+	byNo := make(map[int]interface{}, len(values))
+	for name, v := range values {
+		fieldNo := db.fieldNoByName(name)
+		if fieldNo == 0 {
+			panic(fmt.Errorf("%w: %q", ErrFieldNotFound, name))
+		}
+		byNo[fieldNo] = v
+	}
+	for fieldNo := 1; fieldNo <= len(db.fields); fieldNo++ {
+		if v, ok := byNo[fieldNo]; ok {
+			db.setFieldValue(fieldNo, v)
+		}
+	}
+}
+
+func (db *XBase) record() Record {
+	buf := append([]byte(nil), db.buf...)
+	r := Record{
+		RecNo:   db.recNo,
+		Deleted: buf[0] == '*',
+		fields:  db.fields,
+		index:   db.fieldIndex(),
+		values:  make([]Value, len(db.fields)),
+	}
+	for i, f := range db.fields {
+		r.values[i] = db.fieldValue(i+1, f, buf)
+	}
+	return r
+}
+
+func (db *XBase) fieldValue(fieldNo int, f *field, recordBuf []byte) Value {
+	defer onField(fieldNo)
+	return db.readValue(fieldNo, f, recordBuf)
+}
+
+// readValue decodes the field value according to the read policy.
+func (db *XBase) readValue(fieldNo int, f *field, recordBuf []byte) Value {
+	buf, ok := db.cleanField(fieldNo, f, recordBuf)
+	v := Value{Kind: Kind(f.Type), Null: true}
+	if ok {
+		v = f.value(buf, db.decoder, db.loc)
+	} else {
+		v.str = f.stringValue(buf, db.decoder)
+	}
+	v.Raw = f.buffer(recordBuf)
+	return v
+}
+
+// setFieldValue sets the field value, a nil value clears the field.
+func (db *XBase) setFieldValue(fieldNo int, value interface{}) {
+	defer onField(fieldNo)
+	f := db.fieldByNo(fieldNo)
+	if value == nil {
+		db.touch()
+		f.setBytes(db.buf, nil)
+		return
+	}
+	// The record is marked as changed only if the value is written.
+	db.ownBuf()
+	tr := f.setValue(db.buf, value, db.encoder, db.loc, db.writePolicy(fieldNo))
+	db.isDirty = true
+	db.report(fieldNo, tr)
+}
+
+func (f *field) value(recordBuf []byte, dec *encoding.Decoder, loc *time.Location) Value {
+	v := Value{Kind: Kind(f.Type), Raw: f.buffer(recordBuf)}
+	v.str = f.stringValue(recordBuf, dec)
+	if f.Type != 'C' && (isBlank(v.Raw) || (f.Type == 'L' && v.Raw[0] == '?')) {
+		v.Null = true
+		return v
+	}
+	switch f.Type {
+	case 'C':
+		v.v = v.str
+	case 'N':
+		if f.Dec > 0 {
+			v.v = f.floatValue(recordBuf)
+		} else {
+			v.v = f.intValue(recordBuf)
+		}
+	case 'L':
+		v.v = f.boolValue(recordBuf)
+	case 'D':
+		v.v = f.dateValue(recordBuf, loc)
+	}
+	return v
+}
+
+func isBlank(b []byte) bool {
+	for _, c := range b {
+		if c != ' ' {
+			return false
+		}
+	}
+	return true
+}
+
+func normName(name string) string {
+	return strings.ToUpper(strings.TrimSpace(name))
+}
@@ -0,0 +1,109 @@
+package xbase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecord(t *testing.T) {
+	db := New()
+	db.OpenFile("./testdata/rec3.dbf", true)
+
+	db.GoTo(3)
+	r := db.Record()
+	require.NoError(t, db.Error())
+
+	require.Equal(t, int64(3), r.RecNo)
+	require.Equal(t, false, r.Deleted)
+	require.Equal(t, 5, r.Len())
+	require.Equal(t, []string{"NAME", "FLAG", "COUNT", "PRICE", "DATE"}, r.Names())
+
+	name := r.Get("name")
+	require.Equal(t, KindCharacter, name.Kind)
+	require.Equal(t, "Мышь", name.String())
+	require.Equal(t, []byte{0x8C, 0xEB, 0xE8, 0xEC}, name.Raw[:4])
+
+	require.Equal(t, false, r.Get("FLAG").Bool())
+	require.Equal(t, int64(-321), r.Get("COUNT").Int())
+	require.Equal(t, int64(-321), r.Get("COUNT").Interface())
+	require.Equal(t, -54.32, r.Get("PRICE").Float())
+	require.Equal(t, -54.32, r.Get("PRICE").Interface())
+	require.Equal(t, time.Date(2021, 2, 12, 0, 0, 0, 0, time.UTC), r.Get("DATE").Time())
+	require.Equal(t, Kind(0), r.Get("NONE").Kind)
+
+	db.First()
+	require.Equal(t, "Мышь", r.Value(1).String())
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestRecordNull(t *testing.T) {
+	db := New()
+	db.OpenFile("./testdata/rec3.dbf", true)
+
+	db.GoTo(2)
+	m := db.Record().Map()
+	require.NoError(t, db.Error())
+	require.Equal(t, map[string]interface{}{
+		"NAME":  "",
+		"FLAG":  nil,
+		"COUNT": nil,
+		"PRICE": nil,
+		"DATE":  nil,
+	}, m)
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestSetRecord(t *testing.T) {
+	db := New()
+	addFields(db)
+	db.CreateFile("./testdata/test.dbf")
+
+	src := New()
+	src.OpenFile("./testdata/rec3.dbf", true)
+	src.First()
+	for !src.EOF() {
+		db.Add()
+		db.SetRecord(src.Record().Map())
+		db.Save()
+		src.Next()
+	}
+	src.CloseFile()
+	require.NoError(t, src.Error())
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+
+	testBytes := readFile("./testdata/test.dbf")
+	goldBytes := readFile("./testdata/rec3.dbf")
+	require.Equal(t, goldBytes, testBytes)
+}
+
+func TestSetRecordError(t *testing.T) {
+	db := New()
+	addFields(db)
+	db.CreateFile("./testdata/test.dbf")
+	db.Add()
+
+	db.SetRecord(map[string]interface{}{"NONE": 1})
+	require.True(t, errors.Is(db.Error(), ErrFieldNotFound))
+	db.ResetError()
+
+	db.SetRecord(map[string]interface{}{"name": "Abc", "count": "x"})
+	require.True(t, errors.Is(db.Error(), ErrTypeMismatch))
+	var e *Error
+	require.True(t, errors.As(db.Error(), &e))
+	require.Equal(t, 3, e.FieldNo)
+	require.Equal(t, "COUNT", e.Field)
+	require.Equal(t, `xbase: SetRecord: field 3 "COUNT": type mismatch: got "C", want "N"`, e.Error())
+	db.ResetError()
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
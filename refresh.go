@@ -0,0 +1,79 @@
+package xbase
+
+import (
+	"fmt"
+	"io"
+)
+
+// Refresh rereads the header and the field descriptions of the file
+// to see the changes made by other programs, such as appended records.
+// The current record is reread unless it has unsaved changes.
+// If the current record is beyond the new end of file, the file is positioned to EOF.
+//
+// If the structure of the file has changed, the error is ErrStructureChanged
+// and the object keeps the previous state.
+// A file loaded into memory by SetLoadMode is not reread.
+func (db *XBase) Refresh() {
+	if db.err != nil {
+		return
+	}
+	defer db.wrapError("Refresh")
+	db.refresh()
+	if db.recNo > db.recCount() {
+		db.releaseBuf()
+		db.recNo = db.recCount() + 1
+		return
+	}
+	if db.recNo > 0 && !db.isAdd && !db.isDirty {
+		db.readRec()
+	}
+}
+
+// SetAutoRefresh enables rereading the header when moving past the last record,
+// so that records appended by other programs are seen by Next and GoTo.
+// At the end of file Next moves to the first appended record, if any.
+// EOF does not read the file. See the Refresh method for details.
+func (db *XBase) SetAutoRefresh(on bool) {
+	db.autoRefresh = on
+}
+
+// refresh rereads the header and checks that the structure has not changed.
+func (db *XBase) refresh() {
+	db.checkFile()
+	h := &header{}
+	db.fileSeek(0, io.SeekStart)
+	h.read(db.file)
+	fields := readFields(db.file, h.fieldCount())
+	db.checkStructure(h, fields)
+
+	recCount := db.header.RecCount
+	cp := db.header.CP
+	*db.header = *h
+	// The code page may be set by SetCodePage for a file without the mark.
+	db.header.CP = cp
+	db.fileCP = h.CP
+	if db.isMod && recCount > h.RecCount {
+		// Records appended by this object are not in the header on disk yet.
+		db.header.RecCount = recCount
+	}
+	db.cache.count = 0
+}
+
+// checkStructure panics if the file structure differs from the current one.
+func (db *XBase) checkStructure(h *header, fields []*field) {
+	old := db.header
+	switch {
+	case h.DataOffset != old.DataOffset || len(fields) != len(db.fields):
+		panic(fmt.Errorf("%w: field count", ErrStructureChanged))
+	case h.RecSize != old.RecSize:
+		panic(fmt.Errorf("%w: record size", ErrStructureChanged))
+	case h.CP != db.fileCP:
+		panic(fmt.Errorf("%w: code page", ErrStructureChanged))
+	}
+	for i, f := range fields {
+		o := db.fields[i]
+		if f.Name != o.Name || f.Type != o.Type || f.Len != o.Len || f.Dec != o.Dec {
+			panic(fmt.Errorf("%w: field %q", ErrStructureChanged, o.name()))
+		}
+	}
+}
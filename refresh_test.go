@@ -0,0 +1,217 @@
+package xbase
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// appendCount appends records to the count file as another program would.
+func appendCount(t *testing.T, name string, from, to int) {
+	db := New()
+	db.SetDurability(Durability{HeaderInterval: 1})
+	db.OpenFile(name, false)
+	for i := from; i <= to; i++ {
+		db.Add()
+		db.SetFieldValue(1, fmt.Sprintf("Name %d", i))
+		db.SetFieldValue(2, i)
+		db.Save()
+	}
+	require.NoError(t, db.Error())
+	t.Cleanup(func() { db.CloseFile() })
+}
+
+func TestRefresh(t *testing.T) {
+	createCountFile(t, "./testdata/test.dbf", 10)
+
+	db := New()
+	db.OpenFile("./testdata/test.dbf", true)
+	db.Last()
+	require.Equal(t, int64(10), db.RecCount())
+
+	appendCount(t, "./testdata/test.dbf", 11, 15)
+	db.Next()
+	require.True(t, db.EOF())
+	require.Equal(t, int64(10), db.RecCount())
+
+	db.Refresh()
+	require.NoError(t, db.Error())
+	require.Equal(t, int64(15), db.RecCount())
+	require.False(t, db.EOF())
+	require.Equal(t, int64(11), db.RecNo())
+	require.Equal(t, int64(11), db.FieldValueAsInt(2))
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestAutoRefresh(t *testing.T) {
+	createCountFile(t, "./testdata/test.dbf", 10)
+
+	db := New()
+	db.SetAutoRefresh(true)
+	db.OpenFile("./testdata/test.dbf", true)
+
+	n := int64(0)
+	for db.First(); !db.EOF(); db.Next() {
+		n++
+		require.Equal(t, n, db.FieldValueAsInt(2))
+	}
+	require.Equal(t, int64(10), n)
+
+	// EOF does not read the file, Next moves to the appended records
+	appendCount(t, "./testdata/test.dbf", 11, 12)
+	require.True(t, db.EOF())
+	require.Equal(t, int64(10), db.RecCount())
+	for db.Next(); !db.EOF(); db.Next() {
+		n++
+		require.Equal(t, n, db.FieldValueAsInt(2))
+	}
+	require.Equal(t, int64(12), n)
+	require.Equal(t, int64(13), db.RecNo())
+
+	db.Next()
+	require.True(t, db.EOF())
+	require.Equal(t, int64(13), db.RecNo())
+
+	appendCount(t, "./testdata/test.dbf", 13, 13)
+	db.Next()
+	require.False(t, db.EOF())
+	require.Equal(t, int64(13), db.FieldValueAsInt(2))
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestAutoRefreshUnsaved(t *testing.T) {
+	createCountFile(t, "./testdata/test.dbf", 3)
+
+	tb := NewTable()
+	tb.SetAutoRefresh(true)
+	tb.SetSavePolicy(RejectUnsaved)
+	require.NoError(t, tb.Open("./testdata/test.dbf", false))
+	require.NoError(t, tb.Last())
+	require.NoError(t, tb.SetFieldValue(1, "Changed"))
+
+	appendCount(t, "./testdata/test.dbf", 4, 4)
+	require.False(t, tb.EOF())
+	require.True(t, errors.Is(tb.Next(), ErrUnsaved))
+	require.Equal(t, int64(3), tb.RecCount())
+	require.True(t, tb.Modified())
+	require.NoError(t, tb.Cancel())
+
+	require.NoError(t, tb.Next())
+	require.Equal(t, int64(4), tb.RecCount())
+	v, err := tb.FieldValueAsInt(2)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), v)
+	require.NoError(t, tb.Close())
+}
+
+func TestRefreshMemoryMap(t *testing.T) {
+	createCountFile(t, "./testdata/test.dbf", 10)
+
+	db := New()
+	db.SetMemoryMap(true)
+	db.SetAutoRefresh(true)
+	db.OpenFile("./testdata/test.dbf", true)
+	db.Last()
+
+	appendCount(t, "./testdata/test.dbf", 11, 20)
+	db.Next()
+	require.False(t, db.EOF())
+	require.Equal(t, int64(11), db.FieldValueAsInt(2))
+	db.GoTo(20)
+	require.Equal(t, "Name 20", db.FieldValueAsString(1))
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestRefreshStructureChanged(t *testing.T) {
+	createCountFile(t, "./testdata/test.dbf", 10)
+
+	db := New()
+	db.OpenFile("./testdata/test.dbf", true)
+	db.GoTo(5)
+
+	other := New()
+	other.AddField("NAME", "C", 20)
+	other.AddField("COUNT", "N", 10)
+	other.CreateFile("./testdata/test.dbf")
+	other.CloseFile()
+	require.NoError(t, other.Error())
+
+	db.Refresh()
+	require.True(t, errors.Is(db.Error(), ErrStructureChanged))
+	require.Equal(t, `xbase: Refresh: file structure changed: record size`, db.Error().Error())
+	db.ResetError()
+	require.Equal(t, int64(10), db.RecCount())
+	require.Equal(t, int64(5), db.RecNo())
+
+	db.SetAutoRefresh(true)
+	db.GoTo(11)
+	require.True(t, errors.Is(db.Error(), ErrStructureChanged))
+	db.ResetError()
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestTableRefresh(t *testing.T) {
+	createCountFile(t, "./testdata/test.dbf", 3)
+
+	tb := NewTable()
+	require.NoError(t, tb.Open("./testdata/test.dbf", true))
+	appendCount(t, "./testdata/test.dbf", 4, 5)
+	require.NoError(t, tb.Refresh())
+	require.Equal(t, int64(5), tb.RecCount())
+	require.NoError(t, tb.Close())
+}
+
+func TestRefreshCodePage(t *testing.T) {
+	db := New()
+	db.AddField("NAME", "C", 30)
+	db.AddField("COUNT", "N", 10)
+	db.CreateFile("./testdata/test.dbf")
+	db.CloseFile()
+	require.NoError(t, db.Error())
+
+	// a file without the code page mark
+	db = New()
+	db.OpenFile("./testdata/test.dbf", true)
+	require.Equal(t, 0, db.CodePage())
+	db.SetCodePage(866)
+	db.SetAutoRefresh(true)
+
+	appendCount(t, "./testdata/test.dbf", 1, 2)
+	db.Refresh()
+	require.NoError(t, db.Error())
+	require.Equal(t, int64(2), db.RecCount())
+	require.Equal(t, 866, db.CodePage())
+
+	appendCount(t, "./testdata/test.dbf", 3, 3)
+	db.GoTo(3)
+	require.NoError(t, db.Error())
+	require.Equal(t, "Name 3", db.FieldValueAsString(1))
+	db.CloseFile()
+	require.NoError(t, db.Error())
+
+	// the mark written by another program is a structure change
+	db = New()
+	db.OpenFile("./testdata/test.dbf", true)
+	w := New()
+	w.OpenFile("./testdata/test.dbf", false)
+	w.SetCodePage(1251)
+	w.Add()
+	w.Save()
+	w.CloseFile()
+	require.NoError(t, w.Error())
+	db.Refresh()
+	require.Equal(t, `xbase: Refresh: file structure changed: code page`, db.Error().Error())
+	db.ResetError()
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
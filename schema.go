@@ -0,0 +1,177 @@
+package xbase
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldDef describes a field of a DBF file.
+type FieldDef struct {
+	Name  string `json:"name"`            // field name
+	Type  string `json:"type"`            // field type: "C", "N", "L" or "D"
+	Len   int    `json:"len"`             // field length
+	Dec   int    `json:"dec,omitempty"`   // number of decimal places
+	Flags byte   `json:"flags,omitempty"` // field flags
+}
+
+// Schema describes the structure of a DBF file.
+// It can be serialized to JSON.
+type Schema []FieldDef
+
+// SchemaOf returns the schema for the struct type of v.
+// The fields are defined as in the AddStructFields method.
+func SchemaOf(v interface{}) (s Schema, err error) {
+	defer catchError("SchemaOf", &err)
+	return schemaOf(newStructFields(v)), nil
+}
+
+// Validate checks the field definitions as the AddField method does.
+func (s Schema) Validate() (err error) {
+	defer catchError("Validate", &err)
+	s.fields()
+	return nil
+}
+
+// Equal returns true if both schemas have the same fields in the same order.
+// Field names and types are compared case-insensitively.
+func (s Schema) Equal(other Schema) bool {
+	if len(s) != len(other) {
+		return false
+	}
+	for i := range s {
+		if !s[i].equal(other[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// CompatibleWith checks that the records of a file with the schema s
+// can be read as records of the schema expected without loss of data.
+// Every expected field must be present in s with the same type
+// and a length and number of decimal places that fit into the expected field.
+// The order of the fields and extra fields of s are not checked.
+//
+// The returned error lists all mismatches, it matches ErrSchemaMismatch.
+func (s Schema) CompatibleWith(expected Schema) error {
+	var errs mismatchError
+	mismatch := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Sprintf(format, args...))
+	}
+	for _, e := range expected {
+		d, ok := s.lookup(e.Name)
+		if !ok {
+			mismatch("field %q is missing", normName(e.Name))
+			continue
+		}
+		if !strings.EqualFold(d.Type, e.Type) {
+			mismatch("field %q: type %s, want %s", d.Name, d.Type, e.Type)
+			continue
+		}
+		switch strings.ToUpper(d.Type) {
+		case "C":
+			if d.Len > e.Len {
+				mismatch("field %q: len %d, want len <= %d", d.Name, d.Len, e.Len)
+			}
+		case "N":
+			if d.intLen() > e.intLen() || d.Dec > e.Dec {
+				mismatch("field %q: size %d.%d, want %d.%d", d.Name, d.Len, d.Dec, e.Len, e.Dec)
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// mismatchError lists the schema mismatches, one per line.
+type mismatchError []string
+
+func (e mismatchError) Error() string {
+	var b strings.Builder
+	for i, s := range e {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(ErrSchemaMismatch.Error() + ": " + s)
+	}
+	return b.String()
+}
+
+func (e mismatchError) Unwrap() error {
+	return ErrSchemaMismatch
+}
+
+// intLen returns the number of integer digits of a numeric field,
+// the sign included.
+func (d FieldDef) intLen() int {
+	if d.Dec > 0 {
+		return d.Len - d.Dec - 1
+	}
+	return d.Len
+}
+
+func (s Schema) lookup(name string) (FieldDef, bool) {
+	name = normName(name)
+	for _, d := range s {
+		if normName(d.Name) == name {
+			return d, true
+		}
+	}
+	return FieldDef{}, false
+}
+
+func (d FieldDef) equal(other FieldDef) bool {
+	return normName(d.Name) == normName(other.Name) &&
+		strings.EqualFold(d.Type, other.Type) &&
+		d.Len == other.Len && d.Dec == other.Dec && d.Flags == other.Flags
+}
+
+// fields returns the DBF fields for the schema.
+func (s Schema) fields() []*field {
+	fields := make([]*field, len(s))
+	for i, d := range s {
+		fields[i] = d.newField()
+	}
+	return fields
+}
+
+func (d FieldDef) newField() *field {
+	defer onFieldName(d.Name)
+	f := newField(d.Name, d.Type, d.Len, d.Dec)
+	f.setFlags(d.Flags)
+	return f
+}
+
+func schemaOf(fields []*field) Schema {
+	s := make(Schema, len(fields))
+	for i, f := range fields {
+		s[i] = FieldDef{
+			Name:  f.name(),
+			Type:  string([]byte{f.Type}),
+			Len:   int(f.Len),
+			Dec:   int(f.Dec),
+			Flags: f.flags(),
+		}
+	}
+	return s
+}
+
+// Schema returns the structure of the DBF file.
+func (db *XBase) Schema() Schema {
+	return schemaOf(db.fields)
+}
+
+// SetSchema sets the structure of the DBF file.
+// This method can only be used before creating a new file.
+// It replaces the fields added before.
+// If any of the fields is invalid, the structure is not changed.
+func (db *XBase) SetSchema(s Schema) {
+	if db.err != nil {
+		return
+	}
+	defer db.wrapError("SetSchema")
+	db.fields = s.fields()
+	db.index = nil
+}
@@ -0,0 +1,120 @@
+package xbase
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testSchema = Schema{
+	{Name: "NAME", Type: "C", Len: 20},
+	{Name: "FLAG", Type: "L", Len: 1},
+	{Name: "COUNT", Type: "N", Len: 5},
+	{Name: "PRICE", Type: "N", Len: 9, Dec: 2},
+	{Name: "DATE", Type: "D", Len: 8},
+}
+
+func TestSchema(t *testing.T) {
+	db := New()
+	db.OpenFile("./testdata/rec3.dbf", true)
+	s := db.Schema()
+	db.CloseFile()
+	require.NoError(t, db.Error())
+
+	require.Equal(t, testSchema, s)
+	require.True(t, s.Equal(testSchema))
+}
+
+func TestSetSchema(t *testing.T) {
+	db := New()
+	db.AddField("ID", "N", 5)
+	db.SetSchema(testSchema)
+	db.SetCodePage(866)
+	db.CreateFile("./testdata/test.dbf")
+	db.CloseFile()
+	require.NoError(t, db.Error())
+
+	testBytes := readFile("./testdata/test.dbf")
+	goldBytes := readFile("./testdata/rec0.dbf")
+	require.Equal(t, goldBytes, testBytes)
+}
+
+func TestSetSchemaError(t *testing.T) {
+	db := New()
+	db.AddField("ID", "N", 5)
+	db.SetSchema(Schema{{Name: "NAME", Type: "C", Len: 10}, {Name: "PRICE", Type: "N", Len: 30}})
+	require.Error(t, db.Error())
+	require.Equal(t, `xbase: SetSchema: field "PRICE": invalid field len: got 30, want 0 < len <= 19`, db.Error().Error())
+	require.Equal(t, 1, db.FieldCount())
+}
+
+func TestSchemaJSON(t *testing.T) {
+	b, err := json.Marshal(testSchema[2:4])
+	require.NoError(t, err)
+	require.Equal(t, `[{"name":"COUNT","type":"N","len":5},{"name":"PRICE","type":"N","len":9,"dec":2}]`, string(b))
+
+	var s Schema
+	require.NoError(t, json.Unmarshal(b, &s))
+	require.True(t, s.Equal(testSchema[2:4]))
+}
+
+func TestSchemaOf(t *testing.T) {
+	s, err := SchemaOf(struct {
+		Name  string    `dbf:"NAME,C,20"`
+		Flag  bool      `dbf:"FLAG"`
+		Count int       `dbf:"COUNT,N,5"`
+		Price float64   `dbf:"PRICE,N,9,2"`
+		Date  time.Time `dbf:"DATE"`
+	}{})
+	require.NoError(t, err)
+	require.Equal(t, testSchema, s)
+
+	_, err = SchemaOf(struct{ Name string }{})
+	require.Error(t, err)
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	require.Equal(t, "SchemaOf", e.Op)
+	require.Equal(t, "NAME", e.Field)
+}
+
+func TestSchemaValidate(t *testing.T) {
+	require.NoError(t, testSchema.Validate())
+	require.Error(t, Schema{{Name: "NAME", Type: "X", Len: 20}}.Validate())
+}
+
+func TestSchemaEqual(t *testing.T) {
+	s := Schema{{Name: "name", Type: "c", Len: 20}}
+	require.True(t, s.Equal(testSchema[:1]))
+	require.False(t, s.Equal(testSchema[:2]))
+	require.False(t, s.Equal(Schema{{Name: "NAME", Type: "C", Len: 21}}))
+}
+
+func TestSchemaCompatibleWith(t *testing.T) {
+	expected := Schema{
+		{Name: "PRICE", Type: "N", Len: 12, Dec: 2},
+		{Name: "NAME", Type: "C", Len: 30},
+	}
+	require.NoError(t, testSchema.CompatibleWith(expected))
+
+	expected = Schema{
+		{Name: "NAME", Type: "C", Len: 10},
+		{Name: "PRICE", Type: "N", Len: 9, Dec: 3},
+		{Name: "DATE", Type: "C", Len: 8},
+		{Name: "CODE", Type: "C", Len: 8},
+	}
+	err := testSchema.CompatibleWith(expected)
+	require.True(t, errors.Is(err, ErrSchemaMismatch))
+	require.Equal(t, `schema mismatch: field "NAME": len 20, want len <= 10
+schema mismatch: field "PRICE": size 9.2, want 9.3
+schema mismatch: field "DATE": type D, want C
+schema mismatch: field "CODE" is missing`, err.Error())
+	// the decimal point takes one position of the field
+	expected = Schema{{Name: "COUNT", Type: "N", Len: 7, Dec: 2}}
+	require.NoError(t, Schema{{Name: "COUNT", Type: "N", Len: 4}}.CompatibleWith(expected))
+	err = Schema{{Name: "COUNT", Type: "N", Len: 5}}.CompatibleWith(expected)
+	require.Equal(t, `schema mismatch: field "COUNT": size 5.0, want 7.2`, err.Error())
+	require.NoError(t, Schema{{Name: "COUNT", Type: "N", Len: 6, Dec: 1}}.CompatibleWith(expected))
+}
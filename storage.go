@@ -0,0 +1,134 @@
+package xbase
+
+import (
+	"errors"
+	"io"
+)
+
+// Storage is the storage of a DBF file.
+// If it implements io.Closer, it is closed by the CloseFile method.
+type Storage interface {
+	io.ReadWriteSeeker
+}
+
+// CreateStorage creates a new DBF file in the empty storage s.
+func (db *XBase) CreateStorage(s Storage) {
+	if db.err != nil {
+		return
+	}
+	defer db.wrapError("CreateStorage")
+	db.checkFields()
+	db.file = s
+	db.name = ""
+	db.isReadOnly = false
+	db.createFile()
+}
+
+// OpenStorage opens an existing DBF file stored in s.
+func (db *XBase) OpenStorage(s Storage, readOnly bool) {
+	if db.err != nil {
+		return
+	}
+	defer db.wrapError("OpenStorage")
+	db.file = s
+	db.name = ""
+	db.isReadOnly = readOnly
+	db.open()
+}
+
+// MemStorage is a Storage that keeps a DBF file in memory.
+// It is not closed by the CloseFile method,
+// so the contents of the file can be used after closing.
+// The zero value is an empty storage ready to use.
+type MemStorage struct {
+	buf []byte
+	pos int64
+}
+
+// NewMemStorage returns a storage with the contents b.
+// The storage takes ownership of b.
+func NewMemStorage(b []byte) *MemStorage {
+	return &MemStorage{buf: b}
+}
+
+// Bytes returns the contents of the storage.
+// The slice is valid until the next write.
+func (m *MemStorage) Bytes() []byte {
+	return m.buf
+}
+
+// Size returns the size of the contents.
+func (m *MemStorage) Size() int64 {
+	return int64(len(m.buf))
+}
+
+// WriteTo writes the contents of the storage to w.
+// It implements the io.WriterTo interface.
+func (m *MemStorage) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(m.buf)
+	return int64(n), err
+}
+
+// Read implements the io.Reader interface.
+func (m *MemStorage) Read(b []byte) (int, error) {
+	n, err := m.ReadAt(b, m.pos)
+	m.pos += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+// ReadAt implements the io.ReaderAt interface.
+func (m *MemStorage) ReadAt(b []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("xbase.MemStorage.ReadAt: negative offset")
+	}
+	if off >= int64(len(m.buf)) {
+		return 0, io.EOF
+	}
+	n := copy(b, m.buf[off:])
+	if n < len(b) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Write implements the io.Writer interface.
+func (m *MemStorage) Write(b []byte) (int, error) {
+	end := m.pos + int64(len(b))
+	if size := int64(len(m.buf)); end > size {
+		if end > int64(cap(m.buf)) {
+			buf := make([]byte, end, 2*end)
+			copy(buf, m.buf)
+			m.buf = buf
+		} else {
+			m.buf = m.buf[:end]
+		}
+		// clear the gap after seeking beyond the end
+		for i := size; i < m.pos; i++ {
+			m.buf[i] = 0
+		}
+	}
+	copy(m.buf[m.pos:], b)
+	m.pos = end
+	return len(b), nil
+}
+
+// Seek implements the io.Seeker interface.
+func (m *MemStorage) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += m.pos
+	case io.SeekEnd:
+		offset += int64(len(m.buf))
+	default:
+		return 0, errors.New("xbase.MemStorage.Seek: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("xbase.MemStorage.Seek: negative position")
+	}
+	m.pos = offset
+	return offset, nil
+}
@@ -0,0 +1,95 @@
+package xbase
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemStorage(t *testing.T) {
+	m := &MemStorage{}
+	n, err := m.Write([]byte("abc"))
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+
+	pos, err := m.Seek(5, io.SeekStart)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), pos)
+	_, err = m.Write([]byte("de"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("abc\x00\x00de"), m.Bytes())
+	require.Equal(t, int64(7), m.Size())
+
+	pos, err = m.Seek(-2, io.SeekEnd)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), pos)
+	b := make([]byte, 4)
+	n, err = m.Read(b)
+	require.NoError(t, err)
+	require.Equal(t, []byte("de"), b[:n])
+	_, err = m.Read(b)
+	require.Equal(t, io.EOF, err)
+
+	_, err = m.Seek(-1, io.SeekStart)
+	require.Error(t, err)
+
+	var buf bytes.Buffer
+	n64, err := m.WriteTo(&buf)
+	require.NoError(t, err)
+	require.Equal(t, int64(7), n64)
+	require.Equal(t, m.Bytes(), buf.Bytes())
+}
+
+func TestCreateStorage(t *testing.T) {
+	m := &MemStorage{}
+	db := New()
+	addFields(db)
+	db.CreateStorage(m)
+	d := time.Date(2021, 2, 12, 0, 0, 0, 0, time.UTC)
+	db.Add()
+	db.SetFieldValue(1, "Abc")
+	db.SetFieldValue(2, true)
+	db.SetFieldValue(3, 123)
+	db.SetFieldValue(4, 123.45)
+	db.SetFieldValue(5, d)
+	db.Save()
+	db.Add()
+	db.Save()
+	db.Add()
+	db.SetFieldValue(1, "Мышь")
+	db.SetFieldValue(2, false)
+	db.SetFieldValue(3, -321)
+	db.SetFieldValue(4, -54.32)
+	db.SetFieldValue(5, d)
+	db.Save()
+	db.CloseFile()
+	require.NoError(t, db.Error())
+	require.Equal(t, readFile("./testdata/rec3.dbf"), zeroModDate(append([]byte(nil), m.Bytes()...)))
+
+	// edit in memory
+	db = New()
+	db.OpenStorage(m, false)
+	db.GoTo(2)
+	db.SetFieldValue(1, "Кот")
+	db.Save()
+	db.CloseFile()
+	require.NoError(t, db.Error())
+
+	db = New()
+	db.OpenStorage(NewMemStorage(m.Bytes()), true)
+	db.GoTo(2)
+	require.Equal(t, "Кот", db.FieldValueAsString(1))
+	db.SetFieldValue(1, "Пёс")
+	db.Save()
+	require.True(t, errors.Is(db.Error(), ErrReadOnly))
+}
+
+func TestCreateStorageNoFields(t *testing.T) {
+	db := New()
+	db.CreateStorage(&MemStorage{})
+	require.True(t, errors.Is(db.Error(), ErrNoFields))
+}
@@ -0,0 +1,500 @@
+package xbase
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"math/big"
+	"time"
+)
+
+// Table provides the same operations as XBase,
+// but every method returns its error instead of keeping it in the object.
+// An error does not block the following calls.
+//
+// Table is built on XBase, the underlying object is available through the XBase method.
+// The record iterators and the panic mode of XBase have no Table counterparts.
+type Table struct {
+	db *XBase
+}
+
+// NewTable creates a Table object to work with a DBF file.
+func NewTable() *Table {
+	return &Table{db: New()}
+}
+
+// OpenTable opens an existing DBF file and returns a Table object for it.
+func OpenTable(name string, readOnly bool) (*Table, error) {
+	t := NewTable()
+	if err := t.Open(name, readOnly); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// XBase returns the underlying XBase object.
+func (t *Table) XBase() *XBase {
+	return t.db
+}
+
+// AddField adds a field to the structure of the DBF file.
+// See XBase.AddField for details.
+func (t *Table) AddField(name string, typ string, opts ...int) error {
+	t.db.AddField(name, typ, opts...)
+	return t.result()
+}
+
+// AddStructFields adds fields to the structure of the DBF file
+// from the fields of the struct v.
+// See XBase.AddStructFields for details.
+func (t *Table) AddStructFields(v interface{}) error {
+	t.db.AddStructFields(v)
+	return t.result()
+}
+
+// Schema returns the structure of the DBF file.
+func (t *Table) Schema() Schema {
+	return t.db.Schema()
+}
+
+// SetSchema sets the structure of the DBF file.
+// See XBase.SetSchema for details.
+func (t *Table) SetSchema(s Schema) error {
+	t.db.SetSchema(s)
+	return t.result()
+}
+
+// SetCodePage sets the encoding mode for reading and writing string field values.
+// See XBase.SetCodePage for details.
+func (t *Table) SetCodePage(cp int) {
+	t.db.SetCodePage(cp)
+}
+
+// CodePage returns the code page of a DBF file.
+func (t *Table) CodePage() int {
+	return t.db.CodePage()
+}
+
+// ModDate returns the modification date of the DBF file.
+func (t *Table) ModDate() time.Time {
+	return t.db.ModDate()
+}
+
+// SetLocation sets the location of date field values.
+// See XBase.SetLocation for details.
+func (t *Table) SetLocation(loc *time.Location) {
+	t.db.SetLocation(loc)
+}
+
+// SetSavePolicy sets what happens to unsaved changes of the current record.
+// See XBase.SetSavePolicy for details.
+func (t *Table) SetSavePolicy(p SavePolicy) {
+	t.db.SetSavePolicy(p)
+}
+
+// SetWritePolicy sets the write policy for all fields.
+// See XBase.SetWritePolicy for details.
+func (t *Table) SetWritePolicy(p WritePolicy) {
+	t.db.SetWritePolicy(p)
+}
+
+// SetFieldWritePolicy sets the write policy for the field.
+// Fields are numbered starting from 1.
+func (t *Table) SetFieldWritePolicy(fieldNo int, p WritePolicy) error {
+	t.db.SetFieldWritePolicy(fieldNo, p)
+	return t.result()
+}
+
+// SetReadPolicy sets how invalid field values are read.
+// See XBase.SetReadPolicy for details.
+func (t *Table) SetReadPolicy(p ReadPolicy) {
+	t.db.SetReadPolicy(p)
+}
+
+// Location returns the location of date field values.
+func (t *Table) Location() *time.Location {
+	return t.db.Location()
+}
+
+// SetReadAhead sets the size of the block of records read at once on sequential access.
+// See XBase.SetReadAhead for details.
+func (t *Table) SetReadAhead(size int) {
+	t.db.SetReadAhead(size)
+}
+
+// SetMemoryMap enables memory mapping of files opened for reading only.
+// See XBase.SetMemoryMap for details.
+func (t *Table) SetMemoryMap(on bool) {
+	t.db.SetMemoryMap(on)
+}
+
+// MemoryMapped returns true if the file is memory-mapped.
+func (t *Table) MemoryMapped() bool {
+	return t.db.MemoryMapped()
+}
+
+// SetLoadMode sets whether the Open method loads the whole file into memory.
+// See XBase.SetLoadMode for details.
+func (t *Table) SetLoadMode(m LoadMode) {
+	t.db.SetLoadMode(m)
+}
+
+// SetDurability sets how changes reach the disk.
+// See XBase.SetDurability for details.
+func (t *Table) SetDurability(d Durability) {
+	t.db.SetDurability(d)
+}
+
+// Refresh rereads the header to see the changes made by other programs.
+// See XBase.Refresh for details.
+func (t *Table) Refresh() error {
+	t.db.Refresh()
+	return t.result()
+}
+
+// SetAutoRefresh enables rereading the header when moving past the last record.
+// See XBase.SetAutoRefresh for details.
+func (t *Table) SetAutoRefresh(on bool) {
+	t.db.SetAutoRefresh(on)
+}
+
+// Create creates a new file in DBF format.
+// If a file with that name exists, it will be overwritten.
+func (t *Table) Create(name string) error {
+	t.db.CreateFile(name)
+	return t.result()
+}
+
+// Open opens an existing DBF file.
+func (t *Table) Open(name string, readOnly bool) error {
+	t.db.OpenFile(name, readOnly)
+	return t.result()
+}
+
+// CreateStorage creates a new DBF file in the empty storage s.
+func (t *Table) CreateStorage(s Storage) error {
+	t.db.CreateStorage(s)
+	return t.result()
+}
+
+// OpenStorage opens an existing DBF file stored in s.
+func (t *Table) OpenStorage(s Storage, readOnly bool) error {
+	t.db.OpenStorage(s, readOnly)
+	return t.result()
+}
+
+// OpenReaderAt opens a DBF file stored in r for reading.
+// See XBase.OpenReaderAt for details.
+func (t *Table) OpenReaderAt(r io.ReaderAt, size int64) error {
+	t.db.OpenReaderAt(r, size)
+	return t.result()
+}
+
+// OpenReadWriteSeeker opens a DBF file stored in rws for reading and writing.
+// See XBase.OpenReadWriteSeeker for details.
+func (t *Table) OpenReadWriteSeeker(rws io.ReadWriteSeeker) error {
+	t.db.OpenReadWriteSeeker(rws)
+	return t.result()
+}
+
+// OpenFS opens the DBF file with the given name in the file system fsys for reading.
+// See XBase.OpenFS for details.
+func (t *Table) OpenFS(fsys fs.FS, name string) error {
+	t.db.OpenFS(fsys, name)
+	return t.result()
+}
+
+// Close closes a previously opened or created DBF file.
+func (t *Table) Close() error {
+	t.db.CloseFile()
+	return t.result()
+}
+
+// Flush writes the header and the saved changes to the file.
+// See XBase.Flush for details.
+func (t *Table) Flush() error {
+	t.db.Flush()
+	return t.result()
+}
+
+// First positions the table to the first record.
+func (t *Table) First() error {
+	t.db.First()
+	return t.result()
+}
+
+// Last positions the table to the last record.
+func (t *Table) Last() error {
+	t.db.Last()
+	return t.result()
+}
+
+// Next positions the table to the next record.
+func (t *Table) Next() error {
+	t.db.Next()
+	return t.result()
+}
+
+// Prev positions the table to the previous record.
+func (t *Table) Prev() error {
+	t.db.Prev()
+	return t.result()
+}
+
+// GoTo positions the table to a record by its ordinal number.
+// Numbering starts from 1.
+func (t *Table) GoTo(recNo int64) error {
+	t.db.GoTo(recNo)
+	return t.result()
+}
+
+// RecNo returns the sequence number of the current record.
+// Numbering starts from 1.
+func (t *Table) RecNo() int64 {
+	return t.db.RecNo()
+}
+
+// RecCount returns the number of records in the DBF file.
+func (t *Table) RecCount() int64 {
+	return t.db.RecCount()
+}
+
+// FieldCount returns the number of fields in the DBF file.
+func (t *Table) FieldCount() int {
+	return t.db.FieldCount()
+}
+
+// FieldNo returns the number of the field by name.
+// If name is not found returns 0.
+func (t *Table) FieldNo(name string) int {
+	return t.db.FieldNo(name)
+}
+
+// FieldInfo returns field attributes by number.
+// Fields are numbered starting from 1.
+func (t *Table) FieldInfo(fieldNo int) (name, typ string, length, dec int, err error) {
+	name, typ, length, dec = t.db.FieldInfo(fieldNo)
+	return name, typ, length, dec, t.result()
+}
+
+// EOF returns true if end of file is reached.
+func (t *Table) EOF() bool {
+	return t.db.EOF()
+}
+
+// BOF returns true if the beginning of the file is reached.
+func (t *Table) BOF() bool {
+	return t.db.BOF()
+}
+
+// FieldValueAsString returns the string value of the field of the current record.
+// Fields are numbered starting from 1.
+func (t *Table) FieldValueAsString(fieldNo int) (string, error) {
+	v := t.db.FieldValueAsString(fieldNo)
+	return v, t.result()
+}
+
+// FieldValueAsInt returns the integer value of the field of the current record.
+// Field type must be numeric ("N"). Fields are numbered starting from 1.
+func (t *Table) FieldValueAsInt(fieldNo int) (int64, error) {
+	v := t.db.FieldValueAsInt(fieldNo)
+	return v, t.result()
+}
+
+// FieldValueAsFloat returns the float value of the field of the current record.
+// Field type must be numeric ("N"). Fields are numbered starting from 1.
+func (t *Table) FieldValueAsFloat(fieldNo int) (float64, error) {
+	v := t.db.FieldValueAsFloat(fieldNo)
+	return v, t.result()
+}
+
+// FieldValueAsBool returns the boolean value of the field of the current record.
+// Field type must be logical ("L"). Fields are numbered starting from 1.
+func (t *Table) FieldValueAsBool(fieldNo int) (bool, error) {
+	v := t.db.FieldValueAsBool(fieldNo)
+	return v, t.result()
+}
+
+// FieldValueAsDate returns the date value of the field of the current record.
+// Field type must be date ("D"). Fields are numbered starting from 1.
+func (t *Table) FieldValueAsDate(fieldNo int) (time.Time, error) {
+	v := t.db.FieldValueAsDate(fieldNo)
+	return v, t.result()
+}
+
+// FieldValueAsCivilDate returns the calendar date of the field of the current record.
+// Field type must be date ("D"). Fields are numbered starting from 1.
+func (t *Table) FieldValueAsCivilDate(fieldNo int) (Date, error) {
+	v := t.db.FieldValueAsCivilDate(fieldNo)
+	return v, t.result()
+}
+
+// FieldValueAsDecimal returns the exact value of the numeric field of the current record.
+// Field type must be numeric ("N"). Fields are numbered starting from 1.
+func (t *Table) FieldValueAsDecimal(fieldNo int) (*big.Rat, error) {
+	v := t.db.FieldValueAsDecimal(fieldNo)
+	return v, t.result()
+}
+
+// FieldBytes returns the raw bytes of the field of the current record.
+// See XBase.FieldBytes for details.
+func (t *Table) FieldBytes(fieldNo int) ([]byte, error) {
+	v := t.db.FieldBytes(fieldNo)
+	return v, t.result()
+}
+
+// Record returns the values of all fields of the current record.
+// See XBase.Record for details.
+func (t *Table) Record() (Record, error) {
+	v := t.db.Record()
+	return v, t.result()
+}
+
+// SetRecord sets the field values of the current record by field names.
+// See XBase.SetRecord for details.
+func (t *Table) SetRecord(values map[string]interface{}) error {
+	t.db.SetRecord(values)
+	return t.result()
+}
+
+// SetFieldValue sets the field value of the current record.
+// To save the changes, you need to call the Save method.
+func (t *Table) SetFieldValue(fieldNo int, value interface{}) error {
+	t.db.SetFieldValue(fieldNo, value)
+	return t.result()
+}
+
+// SetFieldDecimal sets the exact value of the numeric field of the current record.
+// See XBase.SetFieldDecimal for details.
+func (t *Table) SetFieldDecimal(fieldNo int, value *big.Rat, mode RoundingMode) error {
+	t.db.SetFieldDecimal(fieldNo, value, mode)
+	return t.result()
+}
+
+// SetFieldBytes sets the raw bytes of the field of the current record.
+// See XBase.SetFieldBytes for details.
+func (t *Table) SetFieldBytes(fieldNo int, value []byte) error {
+	t.db.SetFieldBytes(fieldNo, value)
+	return t.result()
+}
+
+// Add adds a new empty record.
+// To save the changes, you need to call the Save method.
+func (t *Table) Add() error {
+	t.db.Add()
+	return t.result()
+}
+
+// Save writes changes of the current record to the file.
+func (t *Table) Save() error {
+	t.db.Save()
+	return t.result()
+}
+
+// Modified returns true if the current record has unsaved changes.
+func (t *Table) Modified() bool {
+	return t.db.Modified()
+}
+
+// Cancel discards unsaved changes of the current record.
+// See XBase.Cancel for details.
+func (t *Table) Cancel() error {
+	t.db.Cancel()
+	return t.result()
+}
+
+// Del marks the current record as "deleted".
+func (t *Table) Del() error {
+	t.db.Del()
+	return t.result()
+}
+
+// Recall removes the deletion mark from the current record.
+func (t *Table) Recall() error {
+	t.db.Recall()
+	return t.result()
+}
+
+// RecDeleted returns the value of the delete flag for the current record.
+func (t *Table) RecDeleted() (bool, error) {
+	v := t.db.RecDeleted()
+	return v, t.result()
+}
+
+// Clear zeroes the field values of the current record.
+func (t *Table) Clear() error {
+	t.db.Clear()
+	return t.result()
+}
+
+// Unmarshal stores the field values of the current record
+// in the struct pointed to by v.
+// See XBase.Unmarshal for details.
+func (t *Table) Unmarshal(v interface{}) error {
+	t.db.Unmarshal(v)
+	return t.result()
+}
+
+// Marshal sets the field values of the current record
+// from the struct or pointer to struct v.
+// To save the changes, you need to call the Save method.
+func (t *Table) Marshal(v interface{}) error {
+	t.db.Marshal(v)
+	return t.result()
+}
+
+// AppendStruct adds a new record with the field values
+// from the struct or pointer to struct v and saves it.
+func (t *Table) AppendStruct(v interface{}) error {
+	t.db.AppendStruct(v)
+	return t.result()
+}
+
+// ReadAll reads all records of the file into the slice pointed to by v.
+// See XBase.ReadAll for details.
+func (t *Table) ReadAll(v interface{}) error {
+	t.db.ReadAll(v)
+	return t.result()
+}
+
+// ReadAllContext reads all records into the slice as the ReadAll method does.
+// See XBase.ReadAllContext for details.
+func (t *Table) ReadAllContext(ctx context.Context, v interface{}, progress ProgressFunc) error {
+	t.db.ReadAllContext(ctx, v, progress)
+	return t.result()
+}
+
+// Transcode converts the character field values of all records to the code page cp.
+// See XBase.Transcode for details.
+func (t *Table) Transcode(cp int) ([]TranscodeIssue, error) {
+	v := t.db.Transcode(cp)
+	return v, t.result()
+}
+
+// TranscodeContext converts the character field values as the Transcode method does.
+// See XBase.TranscodeContext for details.
+func (t *Table) TranscodeContext(ctx context.Context, cp int, progress ProgressFunc) ([]TranscodeIssue, error) {
+	v := t.db.TranscodeContext(ctx, cp, progress)
+	return v, t.result()
+}
+
+// TranscodeTo writes a copy of the file with the code page cp.
+// See XBase.TranscodeTo for details.
+func (t *Table) TranscodeTo(name string, cp int) ([]TranscodeIssue, error) {
+	v := t.db.TranscodeTo(name, cp)
+	return v, t.result()
+}
+
+// TranscodeToContext writes a converted copy of the file as the TranscodeTo method does.
+// See XBase.TranscodeToContext for details.
+func (t *Table) TranscodeToContext(ctx context.Context, name string, cp int, progress ProgressFunc) ([]TranscodeIssue, error) {
+	v := t.db.TranscodeToContext(ctx, name, cp, progress)
+	return v, t.result()
+}
+
+// result returns the error of the last call and resets it.
+func (t *Table) result() error {
+	err := t.db.Error()
+	t.db.ResetError()
+	return err
+}
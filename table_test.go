@@ -0,0 +1,126 @@
+package xbase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenTableError(t *testing.T) {
+	tb, err := OpenTable("./testdata/none.dbf", true)
+	require.Error(t, err)
+	require.Nil(t, tb)
+}
+
+func TestTableRead(t *testing.T) {
+	tb, err := OpenTable("./testdata/rec3.dbf", true)
+	require.NoError(t, err)
+
+	require.NoError(t, tb.First())
+	var names []string
+	for !tb.EOF() {
+		name, err := tb.FieldValueAsString(1)
+		require.NoError(t, err)
+		names = append(names, name)
+		require.NoError(t, tb.Next())
+	}
+	require.Equal(t, []string{"Abc", "", "Мышь"}, names)
+
+	require.NoError(t, tb.GoTo(3))
+	count, err := tb.FieldValueAsInt(3)
+	require.NoError(t, err)
+	require.Equal(t, int64(-321), count)
+
+	require.NoError(t, tb.Close())
+}
+
+func TestTableErrorNotSticky(t *testing.T) {
+	tb, err := OpenTable("./testdata/rec3.dbf", true)
+	require.NoError(t, err)
+	require.NoError(t, tb.First())
+
+	_, err = tb.FieldValueAsBool(1)
+	require.Error(t, err)
+
+	_, err = tb.FieldValueAsString(0)
+	require.Error(t, err)
+	require.Equal(t, "xbase: FieldValueAsString: field 0: field number out of range", err.Error())
+
+	v, err := tb.FieldValueAsBool(2)
+	require.NoError(t, err)
+	require.Equal(t, true, v)
+
+	require.NoError(t, tb.Close())
+}
+
+func TestTableCreate(t *testing.T) {
+	tb := NewTable()
+	require.NoError(t, tb.AddField("NAME", "C", 10))
+	require.Error(t, tb.AddField("COUNT", "X", 5))
+	require.NoError(t, tb.Create("./testdata/test.dbf"))
+
+	require.NoError(t, tb.Add())
+	require.Error(t, tb.SetFieldValue(1, "Too long value"))
+	require.NoError(t, tb.SetFieldValue(1, "Abc"))
+	require.NoError(t, tb.Save())
+	require.Equal(t, int64(1), tb.RecCount())
+	require.NoError(t, tb.Close())
+}
+
+func TestTableRecord(t *testing.T) {
+	tb := NewTable()
+	require.NoError(t, tb.AddField("NAME", "C", 10))
+	require.NoError(t, tb.AddField("COUNT", "N", 5))
+	require.NoError(t, tb.Create("./testdata/test.dbf"))
+	tb.SetSavePolicy(SaveUnsaved)
+	tb.SetWritePolicy(WritePolicy{String: StringTruncate})
+	require.Error(t, tb.SetFieldWritePolicy(3, WritePolicy{}))
+
+	require.NoError(t, tb.Add())
+	require.NoError(t, tb.SetRecord(map[string]interface{}{"NAME": "Too long value", "COUNT": 12}))
+	require.True(t, tb.Modified())
+	require.Error(t, tb.SetRecord(map[string]interface{}{"NONE": 1}))
+	require.NoError(t, tb.Save())
+	require.False(t, tb.Modified())
+
+	require.NoError(t, tb.SetFieldValue(2, 34))
+	require.NoError(t, tb.Cancel())
+	r, err := tb.Record()
+	require.NoError(t, err)
+	require.Equal(t, "Too long v", r.Get("NAME").String())
+	require.Equal(t, int64(12), r.Get("COUNT").Int())
+	require.NoError(t, tb.Close())
+}
+
+func TestTableStruct(t *testing.T) {
+	type rec struct {
+		Name  string `dbf:"NAME,C,10"`
+		Count int    `dbf:"COUNT,N,5"`
+	}
+	tb := NewTable()
+	require.NoError(t, tb.AddStructFields(rec{}))
+	require.Error(t, tb.AddStructFields(1))
+	require.NoError(t, tb.Create("./testdata/test.dbf"))
+
+	name, typ, length, dec, err := tb.FieldInfo(2)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{"COUNT", "N", 5, 0}, []interface{}{name, typ, length, dec})
+	_, _, _, _, err = tb.FieldInfo(3)
+	require.Error(t, err)
+	require.Len(t, tb.Schema(), 2)
+
+	require.NoError(t, tb.AppendStruct(rec{Name: "Abc", Count: 1}))
+	require.Error(t, tb.AppendStruct(rec{Name: "Abc", Count: 123456}))
+	require.NoError(t, tb.AppendStruct(rec{Name: "Def", Count: 2}))
+
+	var r rec
+	require.NoError(t, tb.First())
+	require.NoError(t, tb.Unmarshal(&r))
+	require.Equal(t, rec{Name: "Abc", Count: 1}, r)
+	require.Error(t, tb.Unmarshal(r))
+
+	var recs []rec
+	require.NoError(t, tb.ReadAll(&recs))
+	require.Len(t, recs, 2)
+	require.NoError(t, tb.Close())
+}
@@ -0,0 +1,224 @@
+package xbase
+
+import (
+	"context"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+)
+
+// TranscodeIssue describes a character field value
+// that could not be converted to the target code page exactly.
+type TranscodeIssue struct {
+	RecNo      int64  // record number
+	FieldNo    int    // field number
+	Field      string // field name
+	Value      string // source value
+	Result     string // value written to the file
+	Unmappable bool   // some characters are missing in the target code page
+	Truncated  bool   // the converted value did not fit into the field
+}
+
+// Transcode converts the character field values of all records
+// from the current code page to the code page cp
+// and sets cp as the code page of the file.
+// The file must be opened for writing.
+//
+// Characters missing in the target code page are replaced with '?',
+// values that become longer than the field are truncated.
+// Such values are returned as a list of issues.
+//
+// If cp is 0, the values are stored in UTF-8 and the code page mark is cleared.
+func (db *XBase) Transcode(cp int) []TranscodeIssue {
+	if db.err != nil {
+		return nil
+	}
+	defer db.wrapError("Transcode")
+	return db.transcode(context.Background(), cp, nil)
+}
+
+// TranscodeContext converts the character field values as the Transcode method does.
+// If progress is not nil, it is called after each record.
+//
+// If ctx is done before the conversion starts, the file is not changed
+// and the context error is returned.
+// The file converted in place cannot be left partly converted,
+// so once the conversion has started, it runs to the end.
+// With the AtomicRewrite durability option the file is converted to a temporary file,
+// then the conversion stops as soon as ctx is done and the original file is kept.
+func (db *XBase) TranscodeContext(ctx context.Context, cp int, progress ProgressFunc) []TranscodeIssue {
+	if db.err != nil {
+		return nil
+	}
+	defer db.wrapError("TranscodeContext")
+	return db.transcode(ctx, cp, progress)
+}
+
+func (db *XBase) transcode(ctx context.Context, cp int, progress ProgressFunc) []TranscodeIssue {
+	if db.canRewrite() {
+		return db.transcodeAtomic(ctx, cp, progress)
+	}
+	t := db.newTranscoder(cp)
+	tr := db.newTracker(ctx, progress)
+	// Stopping halfway would leave the records in different code pages.
+	tr.ctx = context.Background()
+	recNo := db.recNo
+	for i := int64(1); i <= db.recCount(); i++ {
+		db.goTo(i)
+		db.ownBuf()
+		t.transcode(i, db.buf)
+		db.writeRec()
+		tr.step()
+	}
+	db.setCodePage(cp)
+	db.isMod = true
+	db.goTo(recNo)
+	return t.issues
+}
+
+// TranscodeTo writes a copy of the file with the code page cp
+// converting the character field values of all records.
+// If a file with that name exists, it will be overwritten.
+// The current file is not changed.
+//
+// Values are converted as in the Transcode method.
+func (db *XBase) TranscodeTo(name string, cp int) []TranscodeIssue {
+	if db.err != nil {
+		return nil
+	}
+	defer db.wrapError("TranscodeTo")
+	return db.transcodeTo(context.Background(), name, cp, nil)
+}
+
+// TranscodeToContext writes a converted copy of the file as the TranscodeTo method does.
+// It stops with the context error when ctx is done and removes the incomplete copy.
+// If progress is not nil, it is called after each record.
+func (db *XBase) TranscodeToContext(ctx context.Context, name string, cp int, progress ProgressFunc) []TranscodeIssue {
+	if db.err != nil {
+		return nil
+	}
+	defer db.wrapError("TranscodeToContext")
+	return db.transcodeTo(ctx, name, cp, progress)
+}
+
+func (db *XBase) transcodeTo(ctx context.Context, name string, cp int, progress ProgressFunc) []TranscodeIssue {
+	t := db.newTranscoder(cp)
+	tr := db.newTracker(ctx, progress)
+	dst := New()
+	// The header and the field descriptors are copied with their reserved bytes,
+	// only the code page is changed.
+	h := *db.header
+	dst.header = &h
+	for _, f := range db.fields {
+		c := *f
+		dst.fields = append(dst.fields, &c)
+	}
+	dst.setCodePage(cp)
+	dst.create(name)
+	defer removeOnPanic(name)
+	defer dst.closeOnPanic()
+
+	recNo := db.recNo
+	for i := int64(1); i <= db.recCount(); i++ {
+		db.goTo(i)
+		copy(dst.buf, db.buf)
+		t.transcode(i, dst.buf)
+		dst.appendRec()
+		tr.step()
+	}
+	dst.close()
+	db.goTo(recNo)
+	return t.issues
+}
+
+type transcoder struct {
+	fields []*field
+	dec    *encoding.Decoder
+	enc    *encoding.Encoder
+	resDec *encoding.Decoder
+	issues []TranscodeIssue
+}
+
+func (db *XBase) newTranscoder(cp int) *transcoder {
+	t := &transcoder{fields: db.fields, dec: db.decoder}
+	if cp != 0 {
+		cm := charMapByPage(cp)
+		if cm == nil {
+			panic(errUnsupportedCodePage(cp))
+		}
+		t.enc = cm.NewEncoder()
+		t.resDec = cm.NewDecoder()
+	}
+	return t
+}
+
+// transcode converts the character fields of the record buffer in place.
+func (t *transcoder) transcode(recNo int64, recordBuf []byte) {
+	for i, f := range t.fields {
+		if f.Type != 'C' {
+			continue
+		}
+		value := f.stringValue(recordBuf, t.dec)
+		s, unmappable := t.encode(value)
+		s, truncated := truncate(s, int(f.Len), t.enc == nil)
+		if unmappable || truncated {
+			result := s
+			if t.enc != nil {
+				result, _ = t.resDec.String(s)
+			}
+			t.issues = append(t.issues, TranscodeIssue{
+				RecNo:      recNo,
+				FieldNo:    i + 1,
+				Field:      f.name(),
+				Value:      value,
+				Result:     result,
+				Unmappable: unmappable,
+				Truncated:  truncated,
+			})
+		}
+		f.setBuffer(recordBuf, padRight(s, int(f.Len)))
+	}
+}
+
+// encode converts the value to the target code page.
+// Characters missing in the code page are replaced with '?'.
+func (t *transcoder) encode(value string) (string, bool) {
+	if t.enc == nil || isASCII(value) {
+		return value, false
+	}
+	if s, err := t.enc.String(value); err == nil {
+		return s, false
+	}
+	var b strings.Builder
+	for _, r := range value {
+		s, err := t.enc.String(string(r))
+		if err != nil {
+			s = "?"
+		}
+		b.WriteString(s)
+	}
+	return b.String(), true
+}
+
+// truncate cuts the value to n bytes on a character boundary.
+func truncate(s string, n int, isUTF8 bool) (string, bool) {
+	if len(s) <= n {
+		return s, false
+	}
+	if isUTF8 {
+		for n > 0 && !utf8.RuneStart(s[n]) {
+			n--
+		}
+	}
+	return s[:n], true
+}
+
+// removeOnPanic removes the incomplete file written by a failed operation.
+func removeOnPanic(name string) {
+	if r := recover(); r != nil {
+		os.Remove(name)
+		panic(r)
+	}
+}
@@ -0,0 +1,93 @@
+package xbase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranscodeTo(t *testing.T) {
+	db := New()
+	db.OpenFile("./testdata/rec3.dbf", true)
+	issues := db.TranscodeTo("./testdata/test_cp.dbf", 1251)
+	require.Empty(t, issues)
+	db.CloseFile()
+	require.NoError(t, db.Error())
+
+	db = New()
+	db.OpenFile("./testdata/test_cp.dbf", true)
+	require.Equal(t, 1251, db.CodePage())
+	require.Equal(t, int64(3), db.RecCount())
+
+	db.GoTo(1)
+	require.Equal(t, "Abc", db.FieldValueAsString(1))
+	require.Equal(t, int64(123), db.FieldValueAsInt(3))
+
+	db.GoTo(3)
+	require.Equal(t, "Мышь", db.FieldValueAsString(1))
+	require.Equal(t, float64(-54.32), db.FieldValueAsFloat(4))
+	require.Equal(t, []byte{0xCC, 0xFB, 0xF8, 0xFC}, db.buf[1:5])
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestTranscodeUnmappable(t *testing.T) {
+	db := New()
+	db.OpenFile("./testdata/rec3.dbf", true)
+	issues := db.TranscodeTo("./testdata/test_cp.dbf", 437)
+	db.CloseFile()
+	require.NoError(t, db.Error())
+
+	require.Len(t, issues, 1)
+	require.Equal(t, int64(3), issues[0].RecNo)
+	require.Equal(t, 1, issues[0].FieldNo)
+	require.Equal(t, "NAME", issues[0].Field)
+	require.Equal(t, "Мышь", issues[0].Value)
+	require.Equal(t, "????", issues[0].Result)
+	require.Equal(t, true, issues[0].Unmappable)
+	require.Equal(t, false, issues[0].Truncated)
+}
+
+func TestTranscodeInPlace(t *testing.T) {
+	db := New()
+	db.AddField("NAME", "C", 6)
+	db.SetCodePage(866)
+	db.CreateFile("./testdata/test_cp.dbf")
+	db.Add()
+	db.SetFieldValue(1, "Мышь")
+	db.Save()
+	db.Add()
+	db.SetFieldValue(1, "Кот")
+	db.Save()
+	db.CloseFile()
+	require.NoError(t, db.Error())
+
+	db = New()
+	db.OpenFile("./testdata/test_cp.dbf", false)
+	issues := db.Transcode(0)
+	db.CloseFile()
+	require.NoError(t, db.Error())
+
+	require.Len(t, issues, 1)
+	require.Equal(t, int64(1), issues[0].RecNo)
+	require.Equal(t, "Мыш", issues[0].Result)
+	require.Equal(t, true, issues[0].Truncated)
+
+	db = New()
+	db.OpenFile("./testdata/test_cp.dbf", true)
+	require.Equal(t, 0, db.CodePage())
+	db.First()
+	require.Equal(t, "Мыш", db.FieldValueAsString(1))
+	db.Next()
+	require.Equal(t, "Кот", db.FieldValueAsString(1))
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestTranscodeUnsupportedCodePage(t *testing.T) {
+	db := New()
+	db.OpenFile("./testdata/rec3.dbf", true)
+	db.TranscodeTo("./testdata/test_cp.dbf", 1)
+	require.Error(t, db.Error())
+}
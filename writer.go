@@ -0,0 +1,245 @@
+package xbase
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Writer writes the records of a DBF file sequentially to an io.Writer,
+// such as an HTTP response.
+//
+// The number of records is stored in the header, which precedes the records.
+// If the number is set with the SetRecCount method, the header is written directly.
+// Otherwise, if the destination is an io.WriteSeeker, the header is patched
+// when the Writer is closed. In other cases the records are spooled
+// to a temporary file and written after the header on closing.
+type Writer struct {
+	db      *XBase
+	w       io.Writer
+	out     *bufio.Writer // destination of the records
+	spool   *os.File      // temporary file for the records
+	start   int64         // position of the header in a seekable destination
+	count   int64         // number of records set by SetRecCount, -1 if unknown
+	written int64         // number of records written
+	started bool
+	broken  bool // writing to the destination failed
+	err     error
+}
+
+// NewWriter returns a Writer that writes a DBF file with the structure s to w.
+func NewWriter(w io.Writer, s Schema) (_ *Writer, err error) {
+	wr := &Writer{db: New(), w: w, count: -1}
+	defer catchError("NewWriter", &err)
+	wr.db.fields = s.fields()
+	wr.db.checkFields()
+	wr.db.layout()
+	wr.db.makeBuf()
+	return wr, nil
+}
+
+// SetRecCount sets the number of records to be written.
+// It must be called before the first record is written.
+func (w *Writer) SetRecCount(n int64) {
+	w.count = n
+}
+
+// SetCodePage sets the code page of the file.
+// It must be called before the first record is written.
+// See XBase.SetCodePage for details.
+func (w *Writer) SetCodePage(cp int) {
+	w.db.SetCodePage(cp)
+}
+
+// SetWritePolicy sets the write policy.
+// See XBase.SetWritePolicy for details.
+func (w *Writer) SetWritePolicy(p WritePolicy) {
+	w.db.SetWritePolicy(p)
+}
+
+// SetLocation sets the location of date field values.
+// See XBase.SetLocation for details.
+func (w *Writer) SetLocation(loc *time.Location) {
+	w.db.SetLocation(loc)
+}
+
+// Write writes a record with the values of the fields in the order of the fields.
+// A nil value leaves the field empty.
+//
+// If a value cannot be written, the record is not written
+// and the following records can be written.
+// An error writing to the destination is returned by all following calls.
+func (w *Writer) Write(values []interface{}) (err error) {
+	if w.err != nil {
+		return w.err
+	}
+	defer w.catchError("Write", &err)
+	if len(values) != len(w.db.fields) {
+		panic(fmt.Errorf("%w: %d values for %d fields", ErrSchemaMismatch, len(values), len(w.db.fields)))
+	}
+	w.newRec()
+	for i, v := range values {
+		w.db.setFieldValue(i+1, v)
+	}
+	w.writeRec()
+	return nil
+}
+
+// WriteMap writes a record with the field values by field names.
+// Fields missing in the map are left empty.
+func (w *Writer) WriteMap(values map[string]interface{}) (err error) {
+	if w.err != nil {
+		return w.err
+	}
+	defer w.catchError("WriteMap", &err)
+	w.newRec()
+	w.db.setRecord(values)
+	w.writeRec()
+	return nil
+}
+
+// WriteStruct writes a record with the field values
+// from the struct or pointer to struct v.
+// See XBase.Marshal for details.
+func (w *Writer) WriteStruct(v interface{}) (err error) {
+	if w.err != nil {
+		return w.err
+	}
+	defer w.catchError("WriteStruct", &err)
+	w.newRec()
+	w.db.marshal(structValue(v))
+	w.writeRec()
+	return nil
+}
+
+// Close writes the end of the file and completes the header.
+// It does not close the underlying writer.
+// If the number of records was set with SetRecCount
+// and a different number was written, an error is returned.
+func (w *Writer) Close() (err error) {
+	if w.err != nil {
+		return w.err
+	}
+	defer w.catchError("Close", &err)
+	defer w.removeSpool()
+	w.output(w.finish)
+	w.err = ErrFileNotOpen
+	return nil
+}
+
+// newRec prepares the record buffer for the next record.
+func (w *Writer) newRec() {
+	w.db.recNo = w.written + 1
+	w.db.clearBuf()
+}
+
+func (w *Writer) writeRec() {
+	if w.count >= 0 && w.written >= w.count {
+		panic(fmt.Errorf("%w: more than %d records", ErrRecCount, w.count))
+	}
+	w.output(func() {
+		w.begin()
+		if _, err := w.out.Write(w.db.buf); err != nil {
+			panic(err)
+		}
+	})
+	w.written++
+}
+
+// output runs fn that writes to the destination.
+// If fn fails, the Writer cannot be used anymore.
+func (w *Writer) output(fn func()) {
+	w.broken = true
+	fn()
+	w.broken = false
+}
+
+// begin writes the header before the first record.
+func (w *Writer) begin() {
+	if w.started {
+		return
+	}
+	w.started = true
+	w.db.header.setModDate(time.Now())
+	if w.count >= 0 {
+		w.db.header.RecCount = uint32(w.count)
+		w.db.writeStructure(w.w)
+		w.out = bufio.NewWriter(w.w)
+		return
+	}
+	if ws, ok := w.w.(io.WriteSeeker); ok {
+		if pos, err := ws.Seek(0, io.SeekCurrent); err == nil {
+			w.start = pos
+			w.db.writeStructure(w.w)
+			w.out = bufio.NewWriter(w.w)
+			return
+		}
+	}
+	f, err := os.CreateTemp("", "xbase-*.dbf")
+	if err != nil {
+		panic(err)
+	}
+	w.spool = f
+	w.out = bufio.NewWriter(f)
+}
+
+// finish writes the end of the file and the header if it was not written.
+func (w *Writer) finish() {
+	w.begin()
+	if w.count >= 0 && w.written != w.count {
+		panic(fmt.Errorf("%w: %d records written, %d expected", ErrRecCount, w.written, w.count))
+	}
+	if _, err := w.out.Write([]byte{fileEnd}); err != nil {
+		panic(err)
+	}
+	if err := w.out.Flush(); err != nil {
+		panic(err)
+	}
+	if w.count >= 0 {
+		return
+	}
+	w.db.header.RecCount = uint32(w.written)
+	if w.spool == nil {
+		// patch the header in the seekable destination
+		ws := w.w.(io.WriteSeeker)
+		if _, err := ws.Seek(w.start, io.SeekStart); err != nil {
+			panic(err)
+		}
+		w.db.header.write(ws)
+		if _, err := ws.Seek(0, io.SeekEnd); err != nil {
+			panic(err)
+		}
+		return
+	}
+	w.db.writeStructure(w.w)
+	if _, err := w.spool.Seek(0, io.SeekStart); err != nil {
+		panic(err)
+	}
+	if _, err := io.Copy(w.w, w.spool); err != nil {
+		panic(err)
+	}
+}
+
+func (w *Writer) removeSpool() {
+	if w.spool != nil {
+		w.spool.Close()
+		os.Remove(w.spool.Name())
+		w.spool = nil
+	}
+}
+
+// catchError converts a panic to an error with the current record number.
+// It must be deferred.
+func (w *Writer) catchError(op string, err *error) {
+	if p := recover(); p != nil {
+		w.db.setError(&Error{Op: op}, p)
+		*err = w.db.err
+		w.db.err = nil
+		if w.broken {
+			w.err = *err
+			w.removeSpool()
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package xbase
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var writerSchema = Schema{
+	{Name: "NAME", Type: "C", Len: 20},
+	{Name: "FLAG", Type: "L", Len: 1},
+	{Name: "COUNT", Type: "N", Len: 5},
+	{Name: "PRICE", Type: "N", Len: 9, Dec: 2},
+	{Name: "DATE", Type: "D", Len: 8},
+}
+
+func writeRec3(t *testing.T, w *Writer) {
+	d := time.Date(2021, 2, 12, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, w.Write([]interface{}{"Abc", true, 123, 123.45, d}))
+	require.NoError(t, w.WriteMap(nil))
+	require.NoError(t, w.WriteMap(map[string]interface{}{
+		"NAME": "Мышь", "FLAG": false, "COUNT": -321, "PRICE": -54.32, "DATE": d,
+	}))
+	require.NoError(t, w.Close())
+}
+
+func zeroModDate(b []byte) []byte {
+	b[1], b[2], b[3] = 0, 0, 0
+	return b
+}
+
+func TestWriterRecCount(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, writerSchema)
+	require.NoError(t, err)
+	w.SetCodePage(866)
+	w.SetRecCount(3)
+	writeRec3(t, w)
+	require.Equal(t, readFile("./testdata/rec3.dbf"), zeroModDate(buf.Bytes()))
+}
+
+func TestWriterSeeker(t *testing.T) {
+	f, err := os.Create("./testdata/test.dbf")
+	require.NoError(t, err)
+	w, err := NewWriter(f, writerSchema)
+	require.NoError(t, err)
+	w.SetCodePage(866)
+	writeRec3(t, w)
+	require.NoError(t, f.Close())
+	require.Equal(t, readFile("./testdata/rec3.dbf"), readFile("./testdata/test.dbf"))
+}
+
+func TestWriterSpool(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, writerSchema)
+	require.NoError(t, err)
+	w.SetCodePage(866)
+	writeRec3(t, w)
+	require.Equal(t, readFile("./testdata/rec3.dbf"), zeroModDate(buf.Bytes()))
+	require.Nil(t, w.spool)
+}
+
+func TestWriterEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, writerSchema)
+	require.NoError(t, err)
+	w.SetCodePage(866)
+	require.NoError(t, w.Close())
+	require.Equal(t, readFile("./testdata/rec0.dbf"), zeroModDate(buf.Bytes()))
+}
+
+func TestWriterStruct(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, writerSchema)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteStruct(testRec{Name: "Abc", Count: 123}))
+	require.NoError(t, w.Close())
+
+	r, err := NewReader(&buf)
+	require.NoError(t, err)
+	rec, err := r.Read()
+	require.NoError(t, err)
+	require.Equal(t, "Abc", rec.Get("NAME").String())
+	require.Equal(t, int64(123), rec.Get("COUNT").Int())
+}
+
+func TestWriterErrors(t *testing.T) {
+	_, err := NewWriter(&bytes.Buffer{}, nil)
+	require.True(t, errors.Is(err, ErrNoFields))
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, writerSchema)
+	require.NoError(t, err)
+	w.SetRecCount(1)
+
+	err = w.Write([]interface{}{"Abc"})
+	require.True(t, errors.Is(err, ErrSchemaMismatch))
+
+	err = w.Write([]interface{}{"Abc", true, 123456, nil, nil})
+	require.True(t, errors.Is(err, ErrValueOverflow))
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	require.Equal(t, "COUNT", e.Field)
+	require.Equal(t, int64(1), e.RecNo)
+
+	require.NoError(t, w.Write([]interface{}{"Abc", true, 123, nil, nil}))
+	err = w.Write([]interface{}{"Abc", true, 123, nil, nil})
+	require.True(t, errors.Is(err, ErrRecCount))
+
+	require.NoError(t, w.Close())
+	require.Error(t, w.Write([]interface{}{"Abc", true, 123, nil, nil}))
+
+	w, err = NewWriter(&bytes.Buffer{}, writerSchema)
+	require.NoError(t, err)
+	w.SetRecCount(2)
+	require.NoError(t, w.Write([]interface{}{"Abc", true, 123, nil, nil}))
+	require.True(t, errors.Is(w.Close(), ErrRecCount))
+}
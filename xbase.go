@@ -2,9 +2,8 @@
 package xbase
 
 import (
-	"fmt"
+	"io"
 	"os"
-	"strings"
 	"time"
 
 	"golang.org/x/text/encoding"
@@ -23,17 +22,36 @@ const (
 )
 
 type XBase struct {
-	header  *header
-	fields  []*field
-	file    *os.File
-	buf     []byte
-	err     error
-	recNo   int64
-	isAdd   bool
-	isMod   bool
-	isPanic bool
-	encoder *encoding.Encoder
-	decoder *encoding.Decoder
+	header        *header
+	fields        []*field
+	index         map[string]int
+	file          Storage
+	name          string
+	buf           []byte
+	recBuf        []byte
+	mapping       []byte
+	err           error
+	recNo         int64
+	isAdd         bool
+	isMod         bool
+	isDirty       bool
+	isPanic       bool
+	isReadOnly    bool
+	isMapped      bool
+	useMmap       bool
+	autoRefresh   bool
+	loadMode      LoadMode
+	durability    Durability
+	unflushed     int
+	savePolicy    SavePolicy
+	policy        WritePolicy
+	fieldPolicies map[int]*WritePolicy
+	readPolicy    ReadPolicy
+	fileCP        byte
+	loc           *time.Location
+	cache         readCache
+	encoder       *encoding.Encoder
+	decoder       *encoding.Decoder
 }
 
 type cPage struct {
@@ -104,11 +122,23 @@ func pageByCode(code byte) int {
 	return 0
 }
 
+// SavePolicy defines what happens to unsaved changes of the current record.
+type SavePolicy int
+
+// Save policies.
+const (
+	DiscardUnsaved SavePolicy = iota // changes are lost
+	SaveUnsaved                      // changes are saved, as dBase does
+	RejectUnsaved                    // the operation fails with ErrUnsaved
+)
+
 // Public
 
 // New creates a XBase object to work with a DBF file.
 func New() *XBase {
-	return &XBase{header: newHeader()}
+	db := &XBase{header: newHeader()}
+	db.cache.size = defaultReadAhead
+	return db
 }
 
 // CreateFile creates a new file in DBF format.
@@ -118,15 +148,7 @@ func (db *XBase) CreateFile(name string) {
 		return
 	}
 	defer db.wrapError("CreateFile")
-	db.checkFields()
-	db.fileCreate(name)
-	db.header.setFieldCount(len(db.fields))
-	db.header.RecSize = db.calcRecSize()
-	db.writeHeader()
-	db.writeFields()
-	db.fileWrite([]byte{headerEnd})
-	db.makeBuf()
-	db.isMod = true
+	db.create(name)
 }
 
 // OpenFile opens an existing DBF file.
@@ -135,11 +157,7 @@ func (db *XBase) OpenFile(name string, readOnly bool) {
 		return
 	}
 	defer db.wrapError("OpenFile")
-	db.fileOpen(name, readOnly)
-	db.header.read(db.file)
-	db.readFields()
-	db.makeBuf()
-	db.SetCodePage(db.CodePage())
+	db.openFile(name, readOnly)
 }
 
 // CloseFile closes a previously opened or created DBF file.
@@ -148,12 +166,7 @@ func (db *XBase) CloseFile() {
 		return
 	}
 	defer db.wrapError("CloseFile")
-	if db.isMod {
-		db.header.setModDate(time.Now())
-		db.writeHeader()
-		db.writeFileEnd()
-	}
-	db.fileClose()
+	db.close()
 }
 
 // First positions the object to the first record.
@@ -171,6 +184,7 @@ func (db *XBase) Last() {
 		return
 	}
 	defer db.wrapError("Last")
+	db.leaveRec()
 	db.goTo(db.recCount())
 }
 
@@ -180,7 +194,13 @@ func (db *XBase) Next() {
 		return
 	}
 	defer db.wrapError("Next")
-	db.goTo(db.recNo + 1)
+	db.leaveRec()
+	recNo := db.recNo + 1
+	if db.autoRefresh && db.recNo > db.recCount() {
+		// At the end of file the next record is the first appended one.
+		recNo = db.recNo
+	}
+	db.goTo(recNo)
 }
 
 // Prev positions the object to the previous record.
@@ -235,7 +255,11 @@ func (db *XBase) FieldValueAsInt(fieldNo int) int64 {
 		return 0
 	}
 	defer db.wrapFieldError("FieldValueAsInt", fieldNo)
-	return db.fieldByNo(fieldNo).intValue(db.buf)
+	f, buf, ok := db.readField(fieldNo, 'N')
+	if !ok {
+		return 0
+	}
+	return f.intValue(buf)
 }
 
 // FieldValueAsFloat returns the float value of the field of the current record.
@@ -245,7 +269,11 @@ func (db *XBase) FieldValueAsFloat(fieldNo int) float64 {
 		return 0
 	}
 	defer db.wrapFieldError("FieldValueAsFloat", fieldNo)
-	return db.fieldByNo(fieldNo).floatValue(db.buf)
+	f, buf, ok := db.readField(fieldNo, 'N')
+	if !ok {
+		return 0
+	}
+	return f.floatValue(buf)
 }
 
 // FieldValueAsBool returns the boolean value of the field of the current record.
@@ -255,7 +283,11 @@ func (db *XBase) FieldValueAsBool(fieldNo int) bool {
 		return false
 	}
 	defer db.wrapFieldError("FieldValueAsBool", fieldNo)
-	return db.fieldByNo(fieldNo).boolValue(db.buf)
+	f, buf, ok := db.readField(fieldNo, 'L')
+	if !ok {
+		return false
+	}
+	return f.boolValue(buf)
 }
 
 // FieldValueAsDate returns the date value of the field of the current record.
@@ -266,7 +298,40 @@ func (db *XBase) FieldValueAsDate(fieldNo int) time.Time {
 		return d
 	}
 	defer db.wrapFieldError("FieldValueAsDate", fieldNo)
-	return db.fieldByNo(fieldNo).dateValue(db.buf)
+	return db.fieldByNo(fieldNo).dateValue(db.buf, db.loc)
+}
+
+// FieldBytes returns the raw bytes of the field of the current record.
+// The returned slice refers to the record buffer and is valid
+// until the current record is changed. It must not be modified.
+// In the memory-mapped mode a copy is returned,
+// since the mapping is released when the file is closed or remapped.
+// Fields are numbered starting from 1.
+func (db *XBase) FieldBytes(fieldNo int) []byte {
+	if db.err != nil {
+		return nil
+	}
+	defer db.wrapFieldError("FieldBytes", fieldNo)
+	b := db.fieldByNo(fieldNo).buffer(db.buf)
+	if db.isMapped {
+		b = append([]byte(nil), b...)
+	}
+	return b
+}
+
+// SetFieldBytes sets the raw bytes of the field of the current record
+// without any conversion. A value shorter than the field is padded with spaces.
+// To save the changes, you need to call the Save method.
+// Fields are numbered starting from 1.
+func (db *XBase) SetFieldBytes(fieldNo int, value []byte) {
+	if db.err != nil {
+		return
+	}
+	defer db.wrapFieldError("SetFieldBytes", fieldNo)
+	f := db.fieldByNo(fieldNo)
+	f.checkLen(len(value))
+	db.touch()
+	f.setBytes(db.buf, value)
 }
 
 // SetFieldValue sets the field value of the current record.
@@ -277,7 +342,11 @@ func (db *XBase) SetFieldValue(fieldNo int, value interface{}) {
 		return
 	}
 	defer db.wrapFieldError("SetFieldValue", fieldNo)
-	db.fieldByNo(fieldNo).setValue(db.buf, value, db.encoder)
+	f := db.fieldByNo(fieldNo)
+	db.ownBuf()
+	tr := f.setValue(db.buf, value, db.encoder, db.loc, db.writePolicy(fieldNo))
+	db.isDirty = true
+	db.report(fieldNo, tr)
 }
 
 // Add adds a new empty record.
@@ -287,26 +356,57 @@ func (db *XBase) Add() {
 		return
 	}
 	defer db.wrapError("Add")
+	db.leaveRec()
 	db.isAdd = true
+	db.touch()
 	db.clearBuf()
 }
 
 // Save writes changes to the file.
 // Before calling it, all changes to the object were made
-// only in memory and will be lost when you move to another record
-// or close the file.
+// only in memory. What happens to them when you move to another record
+// or close the file is defined by the SetSavePolicy method.
+// By default they are lost.
 func (db *XBase) Save() {
 	if db.err != nil {
 		return
 	}
 	defer db.wrapError("Save")
-	if db.isAdd {
-		db.appendRec()
-		db.isAdd = false
+	db.save()
+}
+
+// Modified returns true if the current record has changes
+// that are not saved to the file.
+func (db *XBase) Modified() bool {
+	return db.isDirty
+}
+
+// Cancel discards unsaved changes of the current record
+// and reloads it from the file.
+// A record added by the Add method is discarded.
+func (db *XBase) Cancel() {
+	if db.err != nil {
+		return
+	}
+	defer db.wrapError("Cancel")
+	db.cancel()
+}
+
+func (db *XBase) cancel() {
+	db.isAdd = false
+	db.isDirty = false
+	if db.recNo >= 1 && db.recNo <= db.recCount() {
+		db.readRec()
 	} else {
-		db.writeRec()
+		db.clearBuf()
 	}
-	db.isMod = true
+}
+
+// SetSavePolicy sets what happens to unsaved changes of the current record
+// when you move to another record, add a record or close the file.
+// The default policy is DiscardUnsaved.
+func (db *XBase) SetSavePolicy(p SavePolicy) {
+	db.savePolicy = p
 }
 
 // Del marks the current record as "deleted".
@@ -317,6 +417,7 @@ func (db *XBase) Del() {
 		return
 	}
 	defer db.wrapError("Del")
+	db.touch()
 	db.buf[0] = '*'
 }
 
@@ -335,6 +436,7 @@ func (db *XBase) Recall() {
 		return
 	}
 	defer db.wrapError("Recall")
+	db.touch()
 	db.buf[0] = ' '
 }
 
@@ -344,6 +446,7 @@ func (db *XBase) Clear() {
 		return
 	}
 	defer db.wrapError("Clear")
+	db.touch()
 	db.clearBuf()
 }
 
@@ -376,13 +479,7 @@ func (db *XBase) FieldInfo(fieldNo int) (name, typ string, length, dec int) {
 // If name is not found returns 0.
 // Fields are numbered starting from 1.
 func (db *XBase) FieldNo(name string) int {
-	name = strings.ToUpper(strings.TrimSpace(name))
-	for i, f := range db.fields {
-		if f.name() == name {
-			return i + 1
-		}
-	}
-	return 0
+	return db.fieldNoByName(name)
 }
 
 // AddField adds a field to the structure of the DBF file.
@@ -402,7 +499,7 @@ func (db *XBase) AddField(name string, typ string, opts ...int) {
 	if db.err != nil {
 		return
 	}
-	defer db.wrapError(fmt.Sprintf("AddField: field %q", name))
+	defer db.wrapFieldNameError("AddField", name)
 	length := 0
 	dec := 0
 	if len(opts) > 0 {
@@ -413,6 +510,7 @@ func (db *XBase) AddField(name string, typ string, opts ...int) {
 	}
 	f := newField(name, typ, length, dec)
 	db.fields = append(db.fields, f)
+	db.index = nil
 }
 
 // SetCodePage sets the encoding mode for reading and writing string field values.
@@ -459,6 +557,11 @@ func (db *XBase) Error() error {
 	return db.err
 }
 
+// ResetError clears the error, so that the object can be used again.
+func (db *XBase) ResetError() {
+	db.err = nil
+}
+
 // SetPanic sets panic mode on errors.
 // By default, the object does not panic.
 func (db *XBase) SetPanic(flag bool) {
@@ -472,24 +575,102 @@ func (db *XBase) IsPanic() bool {
 
 // Private
 
-func (db *XBase) writeFileEnd() {
-	size := int64(db.header.DataOffset) + db.RecCount()*int64(db.header.RecSize) + 1
-	// check file size
-	fi, err := db.file.Stat()
-	if err != nil {
-		panic(err)
+func (db *XBase) create(name string) {
+	db.checkFields()
+	db.fileCreate(name)
+	db.createFile()
+}
+
+// createFile writes the structure of a new file.
+func (db *XBase) createFile() {
+	db.cache.reset()
+	db.header.RecCount = 0
+	db.recNo = 0
+	db.layout()
+	db.checkWritable()
+	db.fileSeek(0, io.SeekStart)
+	db.writeStructure(db.file)
+	db.fileCP = db.header.CP
+	db.makeBuf()
+	db.isMod = true
+}
+
+func (db *XBase) openFile(name string, readOnly bool) {
+	db.fileOpen(name, readOnly)
+	if db.loadMode != LoadOff {
+		db.loadFile(name)
+	}
+	db.open()
+	if readOnly && db.useMmap {
+		db.mapFile()
+	}
+}
+
+// open reads the structure of the opened file.
+func (db *XBase) open() {
+	defer db.closeOnPanic()
+	db.cache.reset()
+	db.fileSeek(0, io.SeekStart)
+	db.header.read(db.file)
+	db.fileCP = db.header.CP
+	db.readFields(db.file)
+	db.makeBuf()
+	db.SetCodePage(db.CodePage())
+}
+
+func (db *XBase) close() {
+	db.leaveRec()
+	if db.isMod {
+		db.header.setModDate(time.Now())
+		db.writeHeader()
+		db.writeFileEnd()
+	}
+	db.syncOnClose()
+	db.unmapFile()
+	db.fileClose()
+	db.name = ""
+	db.isMod = false
+	db.isAdd = false
+	db.isDirty = false
+}
+
+func (db *XBase) setCodePage(cp int) {
+	if cp == 0 {
+		db.encoder = nil
+		db.decoder = nil
+		db.header.CP = 0
+		return
 	}
-	if fi.Size()+1 == size {
-		db.fileSeek(0, 2) // end file position
+	cm := charMapByPage(cp)
+	if cm == nil {
+		panic(errUnsupportedCodePage(cp))
+	}
+	db.encoder = cm.NewEncoder()
+	db.decoder = cm.NewDecoder()
+	db.header.setCodePage(cp)
+}
+
+// writeFileEnd writes the end of file mark after the last record.
+// Data beyond the mark is ignored.
+func (db *XBase) writeFileEnd() {
+	end := int64(db.header.DataOffset) + db.recCount()*int64(db.header.RecSize)
+	// Data after the last record may be records appended by another program,
+	// the mark is written only if the file ends with the last record or the mark.
+	if size := db.fileSeek(0, io.SeekEnd); size == end || size == end+1 {
+		db.fileSeek(end, io.SeekStart)
 		db.fileWrite([]byte{fileEnd})
 	}
 }
 
 func (db *XBase) goTo(recNo int64) {
+	db.leaveRec()
 	if recNo < 1 {
 		db.recNo = 0
 		return
 	}
+	if recNo > db.recCount() && db.autoRefresh {
+		db.refresh()
+	}
 	if recNo > db.recCount() {
 		db.recNo = db.recCount() + 1
 		return
@@ -498,8 +679,46 @@ func (db *XBase) goTo(recNo int64) {
 	db.readRec()
 }
 
+func (db *XBase) save() {
+	added := db.isAdd
+	if db.isAdd {
+		db.appendRec()
+		db.isAdd = false
+	} else {
+		db.writeRec()
+	}
+	db.isMod = true
+	db.isDirty = false
+	db.saved(added)
+}
+
+// touch marks the current record as changed.
+// It must be called before the record buffer is modified.
+func (db *XBase) touch() {
+	db.ownBuf()
+	db.isDirty = true
+}
+
+// leaveRec applies the save policy to unsaved changes of the current record.
+func (db *XBase) leaveRec() {
+	if !db.isDirty {
+		return
+	}
+	switch db.savePolicy {
+	case SaveUnsaved:
+		db.save()
+	case RejectUnsaved:
+		panic(ErrUnsaved)
+	default:
+		db.isAdd = false
+		db.isDirty = false
+	}
+}
+
 func (db *XBase) makeBuf() {
-	db.buf = make([]byte, int(db.header.RecSize))
+	// extra byte for the end of file mark written by appendRec
+	db.buf = make([]byte, int(db.header.RecSize), int(db.header.RecSize)+1)
+	db.recBuf = db.buf
 }
 
 func (db *XBase) fieldByNo(fieldNo int) *field {
@@ -507,69 +726,65 @@ func (db *XBase) fieldByNo(fieldNo int) *field {
 	return db.fields[fieldNo-1]
 }
 
+// fieldIndex returns the field numbers by names.
+func (db *XBase) fieldIndex() map[string]int {
+	if db.index == nil {
+		db.index = make(map[string]int, len(db.fields))
+		for i := len(db.fields) - 1; i >= 0; i-- {
+			db.index[db.fields[i].name()] = i + 1
+		}
+	}
+	return db.index
+}
+
+func (db *XBase) fieldNoByName(name string) int {
+	return db.fieldIndex()[normName(name)]
+}
+
 func (db *XBase) recCount() int64 {
 	return int64(db.header.RecCount)
 }
 
 func (db *XBase) checkFile() {
 	if db.file == nil {
-		panic(fmt.Errorf("file not open"))
+		panic(ErrFileNotOpen)
 	}
 }
 
 func (db *XBase) checkFields() {
 	if len(db.fields) == 0 {
-		panic(fmt.Errorf("file structure undefined"))
+		panic(ErrNoFields)
 	}
 }
 
 func (db *XBase) checkFieldNo(fieldNo int) {
 	if fieldNo < 1 || fieldNo > len(db.fields) {
-		panic(fmt.Errorf("field number out of range"))
+		panic(ErrFieldOutOfRange)
 	}
 }
 
 func (db *XBase) checkRecNo() {
 	if db.recNo > db.recCount() {
-		panic(fmt.Errorf("file is EOF"))
+		panic(ErrEOF)
 	}
 	if db.recNo < 1 {
-		panic(fmt.Errorf("file is BOF"))
-	}
-}
-
-func (db *XBase) wrapError(s string) {
-	if r := recover(); r != nil {
-		db.err = fmt.Errorf("xbase: %s: %w", s, r)
-		if db.isPanic {
-			panic(db.err)
-		}
+		panic(ErrBOF)
 	}
 }
 
-func (db *XBase) wrapFieldError(s string, fieldNo int) {
-	if r := recover(); r != nil {
-		prefix := fmt.Sprintf("xbase: %s: field %d", s, fieldNo)
-		if fieldNo < 1 || fieldNo > len(db.fields) {
-			db.err = fmt.Errorf("%s: %w", prefix, r)
-		} else {
-			db.err = fmt.Errorf("%s %q: %w", prefix, db.fields[fieldNo-1].name(), r)
-		}
-		if db.isPanic {
-			panic(db.err)
-		}
-	}
+func (db *XBase) recOffset() int64 {
+	return int64(db.header.DataOffset) + int64(db.header.RecSize)*(db.recNo-1)
 }
 
 func (db *XBase) seekRec() {
-	offset := int64(db.header.DataOffset) + int64(db.header.RecSize)*(db.recNo-1)
-	db.fileSeek(offset, 0)
+	db.fileSeek(db.recOffset(), 0)
 }
 
+// appendRec writes the record followed by the end of file mark.
 func (db *XBase) appendRec() {
 	db.recNo = db.recCount() + 1
 	db.seekRec()
-	db.fileWrite(db.buf)
+	db.fileWrite(append(db.buf, fileEnd))
 	db.header.RecCount++
 }
 
@@ -577,10 +792,18 @@ func (db *XBase) writeRec() {
 	db.checkRecNo()
 	db.seekRec()
 	db.fileWrite(db.buf)
+	db.writeCached()
 }
 
 func (db *XBase) readRec() {
 	db.checkRecNo()
+	if db.mapping != nil && db.readMapped() {
+		return
+	}
+	db.releaseBuf()
+	if db.readCached() {
+		return
+	}
 	db.seekRec()
 	db.fileRead(db.buf)
 }
@@ -594,32 +817,56 @@ func (db *XBase) calcRecSize() uint16 {
 }
 
 func (db *XBase) writeHeader() {
+	db.unflushed = 0
+	db.checkWritable()
 	db.fileSeek(0, 0)
 	db.header.write(db.file)
+	db.fileCP = db.header.CP
 }
 
-func (db *XBase) writeFields() {
+// layout sets the field count, the record size and the field offsets.
+func (db *XBase) layout() {
+	db.header.setFieldCount(len(db.fields))
+	db.header.RecSize = db.calcRecSize()
 	offset := 1 // deleted mark
 	for _, f := range db.fields {
 		f.Offset = uint32(offset)
-		f.write(db.file)
 		offset += int(f.Len)
 	}
 }
 
-func (db *XBase) readFields() {
+// writeStructure writes the header, the field descriptors
+// and the header terminator.
+func (db *XBase) writeStructure(writer io.Writer) {
+	db.header.write(writer)
+	for _, f := range db.fields {
+		f.write(writer)
+	}
+	if _, err := writer.Write([]byte{headerEnd}); err != nil {
+		panic(err)
+	}
+}
+
+func (db *XBase) readFields(reader io.Reader) {
+	db.index = nil
+	db.fields = readFields(reader, db.header.fieldCount())
+}
+
+func readFields(reader io.Reader, count int) []*field {
+	fields := make([]*field, 0, count)
 	offset := 1 // deleted mark
-	count := db.header.fieldCount()
 	for i := 0; i < count; i++ {
 		f := &field{}
-		f.read(db.file)
+		f.read(reader)
 		f.Offset = uint32(offset)
-		db.fields = append(db.fields, f)
+		fields = append(fields, f)
 		offset += int(f.Len)
 	}
+	return fields
 }
 
 func (db *XBase) clearBuf() {
+	db.releaseBuf()
 	for i := range db.buf {
 		db.buf[i] = ' '
 	}
@@ -633,6 +880,8 @@ func (db *XBase) fileCreate(name string) {
 		panic(err)
 	}
 	db.file = f
+	db.name = name
+	db.isReadOnly = false
 }
 
 func (db *XBase) fileOpen(name string, readOnly bool) {
@@ -648,32 +897,56 @@ func (db *XBase) fileOpen(name string, readOnly bool) {
 		panic(err)
 	}
 	db.file = f
+	db.name = name
+	db.isReadOnly = readOnly
 }
 
 func (db *XBase) fileClose() {
 	db.checkFile()
-	if err := db.file.Close(); err != nil {
-		panic(err)
+	if c, ok := db.file.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// closeOnPanic closes the file if a panic occurs
+// and passes the panic on.
+func (db *XBase) closeOnPanic() {
+	if r := recover(); r != nil {
+		if c, ok := db.file.(io.Closer); ok {
+			c.Close()
+		}
+		panic(r)
 	}
 }
 
-func (db *XBase) fileSeek(offset int64, whence int) {
+func (db *XBase) fileSeek(offset int64, whence int) int64 {
 	db.checkFile()
-	if _, err := db.file.Seek(offset, whence); err != nil {
+	pos, err := db.file.Seek(offset, whence)
+	if err != nil {
 		panic(err)
 	}
+	return pos
 }
 
 func (db *XBase) fileWrite(b []byte) {
-	db.checkFile()
+	db.checkWritable()
 	if _, err := db.file.Write(b); err != nil {
 		panic(err)
 	}
 }
 
+func (db *XBase) checkWritable() {
+	db.checkFile()
+	if db.isReadOnly {
+		panic(ErrReadOnly)
+	}
+}
+
 func (db *XBase) fileRead(b []byte) {
 	db.checkFile()
-	if _, err := db.file.Read(b); err != nil {
+	if _, err := io.ReadFull(db.file, b); err != nil {
 		panic(err)
 	}
 }
@@ -1,6 +1,7 @@
 package xbase
 
 import (
+	"errors"
 	"io/ioutil"
 	"os"
 	"testing"
@@ -323,3 +324,194 @@ func TestCreateEditRec(t *testing.T) {
 	db.CloseFile()
 	require.NoError(t, db.Error())
 }
+
+func TestFieldBytes(t *testing.T) {
+	db := New()
+	db.OpenFile("./testdata/rec3.dbf", true)
+
+	db.First()
+	require.Equal(t, []byte("Abc                 "), db.FieldBytes(1))
+	require.Equal(t, []byte("  123"), db.FieldBytes(3))
+
+	db.Last()
+	require.Equal(t, []byte{0x8C, 0xEB, 0xE8, 0xEC}, db.FieldBytes(1)[:4])
+
+	db.FieldBytes(6)
+	require.Error(t, db.Error())
+
+	db.CloseFile()
+}
+
+func TestSetFieldBytes(t *testing.T) {
+	src := New()
+	src.OpenFile("./testdata/rec3.dbf", true)
+
+	db := New()
+	addFields(db)
+	db.CreateFile("./testdata/test.dbf")
+
+	src.First()
+	for !src.EOF() {
+		db.Add()
+		for n := 1; n <= src.FieldCount(); n++ {
+			db.SetFieldBytes(n, src.FieldBytes(n))
+		}
+		db.Save()
+		src.Next()
+	}
+
+	src.CloseFile()
+	db.CloseFile()
+	require.NoError(t, src.Error())
+	require.NoError(t, db.Error())
+
+	testBytes := readFile("./testdata/test.dbf")
+	goldBytes := readFile("./testdata/rec3.dbf")
+	require.Equal(t, goldBytes, testBytes)
+
+	db.SetFieldBytes(1, []byte("Ab"))
+	require.Equal(t, []byte("Ab                  "), db.FieldBytes(1))
+
+	db.SetFieldBytes(3, []byte("123456"))
+	require.Error(t, db.Error())
+}
+
+func TestResetError(t *testing.T) {
+	db := New()
+	db.OpenFile("./testdata/rec3.dbf", true)
+	db.First()
+
+	db.FieldValueAsString(0)
+	require.Error(t, db.Error())
+	require.Equal(t, "", db.FieldValueAsString(1))
+
+	db.ResetError()
+	require.NoError(t, db.Error())
+	require.Equal(t, "Abc", db.FieldValueAsString(1))
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestModifiedCancel(t *testing.T) {
+	copyFile("./testdata/rec3.dbf", "./testdata/test1.dbf")
+
+	db := New()
+	db.OpenFile("./testdata/test1.dbf", false)
+
+	db.GoTo(2)
+	require.Equal(t, false, db.Modified())
+	db.SetFieldValue(1, "Edit")
+	require.Equal(t, true, db.Modified())
+
+	db.Cancel()
+	require.Equal(t, false, db.Modified())
+	require.Equal(t, "", db.FieldValueAsString(1))
+
+	db.Add()
+	require.Equal(t, true, db.Modified())
+	db.Cancel()
+	require.Equal(t, false, db.Modified())
+	require.Equal(t, int64(2), db.RecNo())
+	db.Save()
+	require.Equal(t, int64(3), db.RecCount())
+
+	db.Del()
+	require.Equal(t, true, db.Modified())
+	db.Save()
+	require.Equal(t, false, db.Modified())
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestSavePolicyDiscard(t *testing.T) {
+	copyFile("./testdata/rec3.dbf", "./testdata/test1.dbf")
+
+	db := New()
+	db.OpenFile("./testdata/test1.dbf", false)
+
+	db.GoTo(2)
+	db.SetFieldValue(1, "Edit")
+	db.Next()
+	db.Prev()
+	require.Equal(t, "", db.FieldValueAsString(1))
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestSavePolicyAutoSave(t *testing.T) {
+	copyFile("./testdata/rec3.dbf", "./testdata/test1.dbf")
+
+	db := New()
+	db.SetSavePolicy(SaveUnsaved)
+	db.OpenFile("./testdata/test1.dbf", false)
+
+	db.GoTo(2)
+	db.SetFieldValue(1, "Edit")
+	db.Next()
+	db.Prev()
+	require.Equal(t, "Edit", db.FieldValueAsString(1))
+
+	db.Add()
+	db.SetFieldValue(1, "Last")
+	db.Last()
+	require.Equal(t, int64(4), db.RecCount())
+	require.Equal(t, int64(4), db.RecNo())
+	require.Equal(t, "Last", db.FieldValueAsString(1))
+
+	db.Add()
+	db.SetFieldValue(1, "Add")
+	db.CloseFile()
+	require.NoError(t, db.Error())
+
+	db = New()
+	db.OpenFile("./testdata/test1.dbf", true)
+	require.Equal(t, int64(5), db.RecCount())
+	db.Last()
+	require.Equal(t, "Add", db.FieldValueAsString(1))
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}
+
+func TestSavePolicyReject(t *testing.T) {
+	copyFile("./testdata/rec3.dbf", "./testdata/test1.dbf")
+
+	db := New()
+	db.SetSavePolicy(RejectUnsaved)
+	db.OpenFile("./testdata/test1.dbf", false)
+
+	db.GoTo(2)
+	db.SetFieldValue(1, "Edit")
+	db.Next()
+	require.True(t, errors.Is(db.Error(), ErrUnsaved))
+	require.Equal(t, int64(2), db.RecNo())
+	db.ResetError()
+
+	db.CloseFile()
+	require.True(t, errors.Is(db.Error(), ErrUnsaved))
+	db.ResetError()
+
+	db.Save()
+	db.Next()
+	require.Equal(t, int64(3), db.RecNo())
+
+	// a rejected value does not change the record
+	db.SetFieldValue(1, "Too long value for the field")
+	require.True(t, errors.Is(db.Error(), ErrValueOverflow))
+	db.ResetError()
+	db.SetFieldDecimal(3, rat("1234567"), RoundHalfUp)
+	require.True(t, errors.Is(db.Error(), ErrValueOverflow))
+	db.ResetError()
+	db.SetRecord(map[string]interface{}{"COUNT": 1234567})
+	require.True(t, errors.Is(db.Error(), ErrValueOverflow))
+	db.ResetError()
+	require.False(t, db.Modified())
+	db.Prev()
+	require.NoError(t, db.Error())
+	require.Equal(t, int64(2), db.RecNo())
+
+	db.CloseFile()
+	require.NoError(t, db.Error())
+}